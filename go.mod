@@ -4,13 +4,13 @@ go 1.23
 
 require (
 	github.com/go-chi/chi/v5 v5.2.0
+	github.com/swaggest/jsonschema-go v0.3.72
 	github.com/swaggest/openapi-go v0.2.54
 	github.com/swaggest/swgui v1.8.2
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/swaggest/jsonschema-go v0.3.72 // indirect
 	github.com/swaggest/refl v1.3.0 // indirect
 	github.com/vearutop/statigz v1.4.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -1,18 +1,31 @@
 package gocart
 
 import (
+	"bytes"
 	"github.com/benni-tec/gocart/goflag"
 	"github.com/benni-tec/gocart/gotrac"
 	"github.com/benni-tec/gocart/middleware"
 	"io"
 	"net/http"
 	"reflect"
+	"strings"
 )
 
 // Cart represents a gotrac.EndpointFlag that automatically can (de)serialize the request/response
 type Cart interface {
 	goflag.EndpointFlag
 	WithInfo(fn func(info *CartInformation)) Cart
+
+	// WithVerifier adds Verifiers that check the raw request body before it is deserialized, e.g. ContentDigest.
+	// If the input Serializer reads the request itself (see RequestSerializer) the body is buffered in memory to be verified!
+	WithVerifier(verifiers ...Verifier) Cart
+
+	// WithDigest adds a Content-Digest header (RFC 9530) using the given algorithms to serialized responses.
+	// Responses written by a ResponseSerializer do not get a digest.
+	WithDigest(algorithms ...DigestAlgorithm) Cart
+
+	// WithInterceptor attaches Interceptors to the Cart, they are called after the ones attached by the Intercept middleware.
+	WithInterceptor(interceptors ...Interceptor) Cart
 }
 
 // CartFunc is the actual handler that gets the deserialized request (and a HeaderWriter)
@@ -24,6 +37,11 @@ type cartImpl[TInput any, TOutput any] struct {
 	input  Serializer[TInput]
 	output Serializer[TOutput]
 
+	verifiers    []Verifier
+	digests      []DigestAlgorithm
+	interceptors []Interceptor
+	dependencies []reflect.Type
+
 	handler CartFunc[TInput, TOutput]
 }
 
@@ -80,7 +98,9 @@ func (cart *cartImpl[TInput, TOutput]) Info() *goflag.EndpointInformation {
 		handler.Input = gotrac.None[TInput]()
 	}
 
-	if cart.output != nil {
+	if describer, ok := cart.output.(ResponseDescriber); ok {
+		handler.Responses = describer.Responses()
+	} else if cart.output != nil {
 		handler.Output = cart.output.Type()
 	} else {
 		handler.Output = gotrac.None[TOutput]()
@@ -97,25 +117,66 @@ func (cart *cartImpl[TInput, TOutput]) WithInfo(fn func(info *CartInformation))
 	return cart
 }
 
+func (cart *cartImpl[TInput, TOutput]) WithVerifier(verifiers ...Verifier) Cart {
+	cart.verifiers = append(cart.verifiers, verifiers...)
+	return cart
+}
+
+func (cart *cartImpl[TInput, TOutput]) WithDigest(algorithms ...DigestAlgorithm) Cart {
+	for _, alg := range algorithms {
+		if alg.hash() == nil {
+			panic("gocart: unsupported digest algorithm " + string(alg))
+		}
+	}
+
+	cart.digests = append(cart.digests, algorithms...)
+	return cart
+}
+
+func (cart *cartImpl[TInput, TOutput]) WithInterceptor(interceptors ...Interceptor) Cart {
+	cart.interceptors = append(cart.interceptors, interceptors...)
+	return cart
+}
+
+func (cart *cartImpl[TInput, TOutput]) Dependencies() []reflect.Type {
+	return cart.dependencies
+}
+
 func (cart *cartImpl[TInput, TOutput]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	output := negotiate(cart.output, r)
+	if _, ok := cart.output.(Negotiator[TOutput]); ok {
+		// the response depends on the Accept header, so caches have to store it per Accept header
+		w.Header().Add("Vary", "Accept")
+	}
+
 	setHeader(w.Header(), "Accepts", cart.input)
-	setHeader(w.Header(), "Content-Type", cart.output)
+	setContentType(w.Header(), output, r)
 
 	errors := middleware.GetErrors(r.Context())
 
-	input, err := cart.decode(r)
+	input, raw, err := cart.decode(r)
 	if err != nil {
 		errors.AddError(err)
 		return
 	}
 
-	output, err := cart.handler(wrapToBodyRequest[TInput](r, input), w)
+	if closer, ok := any(input).(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	writer := &statusWriter{ResponseWriter: w}
+	result, err := cart.intercept(wrapToBodyRequest[TInput](r, input, raw), writer)
 	if err != nil {
 		errors.AddError(err)
 		return
 	}
 
-	err = cart.encode(w, output)
+	// the handler already wrote a status without a body, e.g. a redirect
+	if !writer.allowsBody() {
+		return
+	}
+
+	err = cart.encode(w, r, output, input, result)
 	if err != nil {
 		errors.AddError(err)
 		return
@@ -137,19 +198,42 @@ var decoderFactories = []DecoderFactory{
 	// TODO: add support for cookies
 }
 
-func (cart *cartImpl[TInput, TOutput]) decode(r *http.Request) (*TInput, error) {
+func (cart *cartImpl[TInput, TOutput]) decode(r *http.Request) (*TInput, []byte, error) {
+	reader, isReader := cart.input.(RequestSerializer[TInput])
+
+	var body []byte
+	if len(cart.verifiers) > 0 || (cart.input != nil && !isReader) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// allow the body to be read again, e.g. by a RequestSerializer or a handler without input Serializer
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		for _, verifier := range cart.verifiers {
+			err = verifier.Verify(r, body)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
 	var input *TInput
 	if cart.input == nil {
 		input = new(TInput)
-	} else {
-		body, err := io.ReadAll(r.Body)
+	} else if isReader {
+		var err error
+		input, err = reader.ReadRequest(r)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-
+	} else {
+		var err error
 		input, err = cart.input.Deserialize(body, r.Header)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -160,24 +244,60 @@ func (cart *cartImpl[TInput, TOutput]) decode(r *http.Request) (*TInput, error)
 	}
 
 	val := reflect.Indirect(reflect.ValueOf(input))
-	typ := val.Type()
-	if typ.Kind() != reflect.Struct {
-		return input, nil
+	if val.Kind() != reflect.Struct {
+		return input, body, nil
 	}
 
+	err := decodeFields(r, val, decoders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return input, body, nil
+}
+
+// decodeFields populates the fields of the struct val, including the ones of embedded structs like OffsetParams
+func decodeFields(r *http.Request, val reflect.Value, decoders []Decoder) error {
+	typ := val.Type()
 	for i := range typ.NumField() {
 		structField := typ.Field(i)
 		field := val.Field(i)
 
+		if !structField.IsExported() && !structField.Anonymous {
+			continue
+		}
+
+		if field.CanInterface() {
+			if params, ok := field.Addr().Interface().(ParamsDecoder); ok {
+				err := params.DecodeParams(r)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if structField.Anonymous {
+			if structField.Type.Kind() == reflect.Struct {
+				err := decodeFields(r, field, decoders)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
 		for _, dec := range decoders {
 			vals, err := dec.Decode(structField)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			err = AssignPrimitives(field, vals)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 
@@ -185,43 +305,80 @@ func (cart *cartImpl[TInput, TOutput]) decode(r *http.Request) (*TInput, error)
 		// https://pkg.go.dev/github.com/swaggest/jsonschema-go#Reflector.Reflect
 	}
 
-	return input, nil
+	return nil
 }
 
-func (cart *cartImpl[TInput, TOutput]) encode(w http.ResponseWriter, output *TOutput) error {
+// encodeHeaders sets the headers declared by (non-zero) fields of output and calls HeaderSetter
+func encodeHeaders[T any](w http.ResponseWriter, r *http.Request, output *T) error {
+	if output == nil {
+		return nil
+	}
+
 	var encoders []Encoder
 	for _, factory := range encoderFactories {
 		encoders = append(encoders, factory(w))
 	}
 
-	if output != nil {
-		val := reflect.Indirect(reflect.ValueOf(output))
-		if val.IsZero() && val.Type().Kind() == reflect.Struct {
-			typ := val.Type()
+	val := reflect.Indirect(reflect.ValueOf(output))
+	if val.Kind() == reflect.Struct {
+		typ := val.Type()
 
-			for i := range typ.NumField() {
-				structField := typ.Field(i)
-				field := val.Field(i)
+		for i := range typ.NumField() {
+			structField := typ.Field(i)
+			field := val.Field(i)
+			if !structField.IsExported() || field.IsZero() {
+				continue
+			}
 
-				for _, dec := range encoders {
-					err := dec.Encode(field, structField)
-					if err != nil {
-						return err
-					}
+			for _, dec := range encoders {
+				err := dec.Encode(field, structField)
+				if err != nil {
+					return err
 				}
-
-				// TODO: add support for json tags, check constraints, ...
-				// https://pkg.go.dev/github.com/swaggest/jsonschema-go#Reflector.Reflect
 			}
+
+			// TODO: add support for json tags, check constraints, ...
+			// https://pkg.go.dev/github.com/swaggest/jsonschema-go#Reflector.Reflect
 		}
 	}
 
-	if cart.output != nil {
-		body, err := cart.output.Serialize(output, w.Header())
+	if setter, ok := any(output).(HeaderSetter); ok {
+		setter.SetHeaders(w.Header(), r)
+	}
+
+	return nil
+}
+
+func (cart *cartImpl[TInput, TOutput]) encode(w http.ResponseWriter, r *http.Request, serializer Serializer[TOutput], input *TInput, output *TOutput) error {
+	err := encodeHeaders(w, r, output)
+	if err != nil {
+		return err
+	}
+
+	if writer, ok := serializer.(ResponseSerializer[TOutput]); ok {
+		return writer.WriteResponse(w, r, output)
+	}
+
+	if serializer != nil {
+		body, err := serializer.Serialize(output, w.Header())
 		if err != nil {
 			return err
 		}
 
+		// apply the sparse fieldset of a Query
+		if selector, ok := any(input).(fieldSelector); ok && strings.Contains(w.Header().Get("Content-Type"), "json") {
+			if target, fields := selector.fieldset(); len(fields) > 0 {
+				body, err = selectFields(body, reflect.TypeFor[TOutput](), target, fields)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(cart.digests) > 0 {
+			w.Header().Set("Content-Digest", formatDigest(body, cart.digests))
+		}
+
 		_, err = w.Write(body)
 		if err != nil {
 			return err
@@ -11,7 +11,8 @@ type DecoderFactory func(request *http.Request) Decoder
 
 // Decoder reads data from HTTP headers, the query, formdata, etc.
 // The returned strings are converted to the proper primitive types.
-// If the receiving field is not an array, but multiple values are provided the first one will be used
+// If the receiving field is not an array, but multiple values are provided the first one will be used.
+// If the value is absent nil is returned, so the field is left unchanged, e.g. keeping the value decoded from the body or by another Decoder
 type Decoder interface {
 	Decode(field reflect.StructField) ([]string, error)
 }
@@ -25,7 +26,7 @@ func NewHeaderDecoder(request *http.Request) Decoder {
 }
 
 func (dec *HeaderDecoder) Decode(field reflect.StructField) ([]string, error) {
-	if tag, ok := field.Tag.Lookup("meta"); ok {
+	if tag, ok := field.Tag.Lookup("meta"); ok && dec.headers.Get(tag) != "" {
 		return strings.Split(dec.headers.Get(tag), ","), nil
 	}
 
@@ -46,7 +47,7 @@ func NewFormDecorder(request *http.Request) Decoder {
 }
 
 func (dec *UrlValuesDecoder) Decode(field reflect.StructField) ([]string, error) {
-	if tag, ok := field.Tag.Lookup(dec.name); ok {
+	if tag, ok := field.Tag.Lookup(dec.name); ok && dec.values.Has(tag) {
 		return strings.Split(dec.values.Get(tag), ","), nil
 	}
 
@@ -62,7 +63,7 @@ func NewPathDecoder(request *http.Request) Decoder {
 }
 
 func (dec *PathDecoder) Decode(field reflect.StructField) ([]string, error) {
-	if tag, ok := field.Tag.Lookup("path"); ok {
+	if tag, ok := field.Tag.Lookup("path"); ok && dec.request.PathValue(tag) != "" {
 		return []string{dec.request.PathValue(tag)}, nil
 	}
 
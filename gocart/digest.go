@@ -0,0 +1,142 @@
+package gocart
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"github.com/benni-tec/gocart/middleware"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// Verifier checks the raw request body before it is deserialized, see Cart.WithVerifier.
+// Returning an error rejects the request, use middleware.Error to choose the status code.
+type Verifier interface {
+	Verify(request *http.Request, body []byte) error
+}
+
+// VerifierFunc implements the Verifier interface using a function.
+type VerifierFunc func(request *http.Request, body []byte) error
+
+func (f VerifierFunc) Verify(request *http.Request, body []byte) error {
+	return f(request, body)
+}
+
+// DigestAlgorithm is a hashing algorithm used in the Content-Digest and Repr-Digest header (RFC 9530).
+type DigestAlgorithm string
+
+const (
+	SHA256 DigestAlgorithm = "sha-256"
+	SHA512 DigestAlgorithm = "sha-512"
+)
+
+// ErrDigestMismatch is returned when the body does not match the digest sent by the client.
+var ErrDigestMismatch = errors.New("gocart: body does not match digest")
+
+// ErrDigestMissing is returned when a digest is required, but the client did not send a supported one.
+var ErrDigestMissing = errors.New("gocart: body digest is missing")
+
+func (alg DigestAlgorithm) hash() hash.Hash {
+	switch alg {
+	case SHA256:
+		return sha256.New()
+	case SHA512:
+		return sha512.New()
+	default:
+		return nil
+	}
+}
+
+// Sum returns the digest of body, it panics if the algorithm is not supported.
+func (alg DigestAlgorithm) Sum(body []byte) []byte {
+	h := alg.hash()
+	if h == nil {
+		panic("gocart: unsupported digest algorithm " + string(alg))
+	}
+
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// ContentDigest returns a Verifier that checks the Content-Digest and Repr-Digest headers (RFC 9530).
+// All digests using a supported algorithm (sha-256, sha-512) have to match the body, otherwise the request is rejected with 400.
+// If required is true, requests without a supported digest are rejected as well.
+//
+// The Repr-Digest is only checked if there is no Content-Encoding, because net/http does not decode the body
+// and thus it is still encoded, while the Repr-Digest covers the decoded representation.
+func ContentDigest(required bool) Verifier {
+	return VerifierFunc(func(request *http.Request, body []byte) error {
+		headers := []string{"Content-Digest"}
+		if request.Header.Get("Content-Encoding") == "" {
+			headers = append(headers, "Repr-Digest")
+		}
+
+		verified := false
+		for _, header := range headers {
+			for alg, digest := range parseDigest(request.Header.Values(header)) {
+				if alg.hash() == nil {
+					continue
+				}
+
+				if !bytes.Equal(alg.Sum(body), digest) {
+					return middleware.Error(http.StatusBadRequest, ErrDigestMismatch)
+				}
+
+				verified = true
+			}
+		}
+
+		if required && !verified {
+			return middleware.Error(http.StatusBadRequest, ErrDigestMissing)
+		}
+
+		return nil
+	})
+}
+
+// formatDigest formats the digests of body as a structured field dictionary (RFC 8941)
+func formatDigest(body []byte, algorithms []DigestAlgorithm) string {
+	fields := make([]string, len(algorithms))
+	for i, alg := range algorithms {
+		fields[i] = string(alg) + "=:" + base64.StdEncoding.EncodeToString(alg.Sum(body)) + ":"
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+// parseDigest parses the dictionary of a digest header, invalid entries are ignored
+func parseDigest(values []string) map[DigestAlgorithm][]byte {
+	digests := map[DigestAlgorithm][]byte{}
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(member), "=")
+			if !ok {
+				continue
+			}
+
+			// ignore parameters
+			value, _, _ = strings.Cut(value, ";")
+			value, ok = strings.CutPrefix(strings.TrimSpace(value), ":")
+			if !ok {
+				continue
+			}
+
+			value, ok = strings.CutSuffix(value, ":")
+			if !ok {
+				continue
+			}
+
+			digest, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				continue
+			}
+
+			digests[DigestAlgorithm(strings.ToLower(key))] = digest
+		}
+	}
+
+	return digests
+}
@@ -0,0 +1,238 @@
+package gocart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/middleware"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// File describes a file that is sent as the response body.
+// It is served using http.ServeContent, therefore Range and conditional requests are supported.
+type File struct {
+	// Content of the file, if it also implements io.Closer it is closed once the response has been written
+	Content io.ReadSeeker
+	// Name of the file, it is used for the Content-Disposition header and to determine the MIME type
+	Name string
+	// ModTime is used for the Last-Modified header as well as If-Modified-Since and If-Range
+	ModTime time.Time
+	// ContentType of the file, if empty it is determined by the extension of Name or by sniffing the content
+	ContentType string
+	// Inline marks the file to be displayed by the browser instead of being downloaded
+	Inline bool
+}
+
+// FileSerializer serves a File using http.ServeContent.
+type FileSerializer struct {
+	contentType []string
+}
+
+// Files Serializer serves a File including Range requests and a Content-Disposition header.
+// The contentType is only used for the documentation, it defaults to application/octet-stream!
+func Files(contentType ...string) Serializer[File] {
+	if len(contentType) == 0 {
+		contentType = []string{"application/octet-stream"}
+	}
+
+	return &FileSerializer{contentType: contentType}
+}
+
+func (s *FileSerializer) Serialize(body *File, headers http.Header) ([]byte, error) {
+	if body == nil || body.Content == nil {
+		return nil, nil
+	}
+
+	defer closeFile(body)
+
+	if body.ContentType != "" {
+		headers.Set("Content-Type", body.ContentType)
+	}
+
+	return io.ReadAll(body.Content)
+}
+
+func (s *FileSerializer) Deserialize(data []byte, headers http.Header) (*File, error) {
+	file := &File{
+		Content:     bytes.NewReader(data),
+		ContentType: headers.Get("Content-Type"),
+	}
+
+	if _, params, err := mime.ParseMediaType(headers.Get("Content-Disposition")); err == nil {
+		file.Name = params["filename"]
+	}
+
+	return file, nil
+}
+
+func (s *FileSerializer) WriteResponse(w http.ResponseWriter, r *http.Request, body *File) error {
+	if body == nil || body.Content == nil {
+		return middleware.Error(http.StatusNotFound, fs.ErrNotExist)
+	}
+
+	defer closeFile(body)
+
+	contentType := body.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(body.Name))
+	}
+
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	} else {
+		// let http.ServeContent sniff the type
+		w.Header().Del("Content-Type")
+	}
+
+	disposition := "attachment"
+	if body.Inline {
+		disposition = "inline"
+	}
+
+	if body.Name != "" {
+		disposition += contentDispositionFilename(body.Name)
+	}
+
+	w.Header().Set("Content-Disposition", disposition)
+
+	http.ServeContent(w, r, body.Name, body.ModTime, body.Content)
+	return nil
+}
+
+func (s *FileSerializer) Type() *goflag.Type {
+	return &goflag.Type{
+		GoType:   reflect.TypeOf([]byte{}),
+		HttpType: s.contentType,
+	}
+}
+
+// Download is used to define a Cart that serves the File returned by h.
+//
+// TInput can still be specified to allow for meta-fields!
+func Download[TInput any](h CartFunc[TInput, File], contentType ...string) Cart {
+	return O[TInput, File](Files(contentType...), h)
+}
+
+// Dir is used to define a Cart that serves the files of fsys.
+// It has to be registered using a wildcard pattern, e.g. "/static/*", the wildcard is then used as the path within fsys.
+//
+// Directories are served using their "index.html", if present.
+func Dir(fsys fs.FS) Cart {
+	return Download(func(request *Request[any], _ HeaderWriter) (*File, error) {
+		name := strings.Trim(request.PathValue("*"), "/")
+		if name == "" {
+			name = "."
+		}
+
+		if !fs.ValidPath(name) {
+			return nil, middleware.Error(http.StatusBadRequest, errors.New("gocart: invalid file path "+name))
+		}
+
+		return openFile(fsys, name)
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("Download a file").
+			WithDescription("Serves the file at the given path, supports range and conditional requests.")
+	})
+}
+
+func openFile(fsys fs.FS, name string) (*File, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		_ = file.Close()
+		return openFile(fsys, path.Join(name, "index.html"))
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	return &File{
+		Content: content,
+		Name:    stat.Name(),
+		ModTime: stat.ModTime(),
+		Inline:  true,
+	}, nil
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return middleware.Error(http.StatusNotFound, err)
+	}
+
+	return err
+}
+
+func closeFile(file *File) {
+	if closer, ok := file.Content.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// contentDispositionFilename returns the filename parameters for a Content-Disposition header,
+// i.e. an ASCII fallback as well as the UTF-8 encoded name (RFC 6266 & RFC 8187)
+func contentDispositionFilename(name string) string {
+	var fallback, encoded strings.Builder
+	ascii := true
+
+	for _, b := range []byte(name) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			encoded.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+
+	for _, r := range name {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteRune('_')
+		case r < 0x20 || r > 0x7e:
+			ascii = false
+			fallback.WriteRune('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	params := "; filename=\"" + fallback.String() + "\""
+	if !ascii {
+		params += "; filename*=UTF-8''" + encoded.String()
+	}
+
+	return params
+}
+
+// isAttrChar reports whether b is allowed unencoded in an RFC 8187 ext-value
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+	}
+}
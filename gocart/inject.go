@@ -0,0 +1,74 @@
+package gocart
+
+import (
+	"github.com/benni-tec/gocart/gotrac"
+	"reflect"
+)
+
+// InjectFunc is a CartFunc that also receives the services it depends on.
+type InjectFunc[TDeps any, TInput any, TOutput any] func(request *Request[TInput], writer HeaderWriter, deps TDeps) (*TOutput, error)
+
+// Inject is used to define a Cart whose handler depends on services provided using gotrac.Provide.
+//
+// If TDeps is a struct each of its exported fields is resolved, otherwise TDeps itself is resolved.
+// The dependencies are checked once the root gotrac.Router serves its first request (or by gotrac.Verify),
+// therefore the providers have to be registered before that!
+func Inject[TDeps any, TInput any, TOutput any](input Serializer[TInput], output Serializer[TOutput], h InjectFunc[TDeps, TInput, TOutput]) Cart {
+	cart := &cartImpl[TInput, TOutput]{
+		input:        input,
+		output:       output,
+		dependencies: dependenciesOf(reflect.TypeFor[TDeps]()),
+	}
+
+	cart.handler = func(request *Request[TInput], writer HeaderWriter) (*TOutput, error) {
+		deps, err := resolveDependencies[TDeps](request)
+		if err != nil {
+			return nil, err
+		}
+
+		return h(request, writer, deps)
+	}
+
+	return cart
+}
+
+func dependenciesOf(typ reflect.Type) []reflect.Type {
+	if typ.Kind() != reflect.Struct {
+		return []reflect.Type{typ}
+	}
+
+	var dependencies []reflect.Type
+	for i := range typ.NumField() {
+		if field := typ.Field(i); field.IsExported() {
+			dependencies = append(dependencies, field.Type)
+		}
+	}
+
+	return dependencies
+}
+
+func resolveDependencies[TDeps any, TInput any](request *Request[TInput]) (TDeps, error) {
+	var deps TDeps
+
+	val := reflect.ValueOf(&deps).Elem()
+	if val.Kind() != reflect.Struct {
+		return gotrac.Resolve[TDeps](request.Context())
+	}
+
+	for i := range val.NumField() {
+		if !val.Type().Field(i).IsExported() {
+			continue
+		}
+
+		instance, err := gotrac.ResolveType(request.Context(), val.Field(i).Type())
+		if err != nil {
+			return deps, err
+		}
+
+		if instance != nil {
+			val.Field(i).Set(reflect.ValueOf(instance))
+		}
+	}
+
+	return deps, nil
+}
@@ -0,0 +1,213 @@
+package gocart
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// Interceptor surrounds the CartFunc of a Cart, see Hooks for typed before and after hooks.
+// Calling Call.Proceed calls the next Interceptor and eventually the CartFunc, not calling it short-circuits them.
+//
+// Interceptors can be attached to a single Cart using Cart.WithInterceptor
+// or to all Carts beneath a gotrac.Router using the Intercept middleware.
+//
+// The order is explicit:
+//   - the Interceptors are called in order, first the ones of the Intercept middlewares (outermost first), then the ones of the Cart
+//   - if an Interceptor returns without proceeding (e.g. a Before hook returns an output or an error), the remaining ones and the CartFunc are skipped
+//   - the After hooks of all interceptors whose Before hook has been called are then called in reverse order
+type Interceptor interface {
+	// Intercept returns the output of the Cart (a *TOutput or nil) and error, usually the ones returned by Call.Proceed
+	Intercept(call *Call) (any, error)
+}
+
+// InterceptorFunc is a function implementing the Interceptor
+type InterceptorFunc func(call *Call) (any, error)
+
+func (fn InterceptorFunc) Intercept(call *Call) (any, error) {
+	return fn(call)
+}
+
+// Call is the call of a CartFunc as seen by an Interceptor
+type Call struct {
+	// Request is the *Request[TInput] of the Cart
+	Request AnyRequest
+	Writer  HeaderWriter
+	// Output is a nil *TOutput describing the output of the Cart
+	Output any
+
+	proceed func() (any, error)
+}
+
+// Proceed calls the remaining Interceptors and the CartFunc and returns their output (a *TOutput or nil) and error
+func (call *Call) Proceed() (any, error) {
+	return call.proceed()
+}
+
+// Hooks is an Interceptor for Carts with the input TInput and output TOutput.
+//
+// TInput and TOutput can also be interfaces, then the Hooks are applied to every Cart
+// whose input and output (pointers) implement them, e.g. Hooks[any, Redactable] for all Carts with a redactable output.
+// In that case Body() and the output point to an interface holding the pointer of the Cart.
+// Hooks whose types do not match a Cart are ignored.
+type Hooks[TInput any, TOutput any] struct {
+	// Before is called after the request has been decoded, but before the CartFunc.
+	// It can modify the request, returning an output or an error short-circuits the CartFunc.
+	Before func(request *Request[TInput], writer HeaderWriter) (*TOutput, error)
+
+	// After is called with the result of the CartFunc (or the short-circuiting Before) and can replace it.
+	After func(request *Request[TInput], output *TOutput, err error) (*TOutput, error)
+}
+
+// Before returns an Interceptor that only has a Before hook.
+func Before[TInput any, TOutput any](fn func(request *Request[TInput], writer HeaderWriter) (*TOutput, error)) Interceptor {
+	return &Hooks[TInput, TOutput]{Before: fn}
+}
+
+// After returns an Interceptor that only has an After hook.
+func After[TInput any, TOutput any](fn func(request *Request[TInput], output *TOutput, err error) (*TOutput, error)) Interceptor {
+	return &Hooks[TInput, TOutput]{After: fn}
+}
+
+func (h *Hooks[TInput, TOutput]) Intercept(call *Call) (any, error) {
+	request, ok := adaptRequest[TInput](call.Request)
+	if !ok || !matches[TOutput](call.Output) {
+		return call.Proceed()
+	}
+
+	if h.Before != nil {
+		output, err := h.Before(request, call.Writer)
+
+		// pass the changes of an adapted request on, e.g. a new context
+		*call.Request.HTTPRequest() = request.Request
+		if output != nil || err != nil {
+			return h.after(request, call.Output, unwrap(call.Output, output), err)
+		}
+	}
+
+	output, err := call.Proceed()
+	return h.after(request, call.Output, output, err)
+}
+
+func (h *Hooks[TInput, TOutput]) after(request *Request[TInput], probe any, output any, err error) (any, error) {
+	if h.After == nil {
+		return output, err
+	}
+
+	var adapted *TOutput
+	if output != nil && !reflect.ValueOf(output).IsNil() {
+		adapted, _ = adapt[TOutput](output)
+	}
+
+	result, err := h.After(request, adapted, err)
+	return unwrap(probe, result), err
+}
+
+// Intercept returns a middleware that attaches the interceptors to all Carts beneath it.
+func Intercept(interceptors ...Interceptor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			combined := slices.Concat(interceptorsFrom(r.Context()), interceptors)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), interceptorsKey{}, combined)))
+		})
+	}
+}
+
+type interceptorsKey struct{}
+
+func interceptorsFrom(ctx context.Context) []Interceptor {
+	interceptors, _ := ctx.Value(interceptorsKey{}).([]Interceptor)
+	return interceptors
+}
+
+// intercept calls the handler surrounded by the interceptors of the context and the Cart
+func (cart *cartImpl[TInput, TOutput]) intercept(request *Request[TInput], writer HeaderWriter) (*TOutput, error) {
+	interceptors := slices.Concat(interceptorsFrom(request.Context()), cart.interceptors)
+	if len(interceptors) == 0 {
+		return cart.handler(request, writer)
+	}
+
+	probe := (*TOutput)(nil)
+
+	var proceed func(i int) (any, error)
+	proceed = func(i int) (any, error) {
+		if i == len(interceptors) {
+			output, err := cart.handler(request, writer)
+			if output == nil {
+				return nil, err
+			}
+
+			return output, err
+		}
+
+		return interceptors[i].Intercept(&Call{
+			Request: request,
+			Writer:  writer,
+			Output:  probe,
+			proceed: func() (any, error) { return proceed(i + 1) },
+		})
+	}
+
+	output, err := proceed(0)
+	if output == nil {
+		return nil, err
+	}
+
+	result, ok := output.(*TOutput)
+	if !ok {
+		return nil, fmt.Errorf("gocart: interceptor returned %T instead of %T", output, probe)
+	}
+
+	return result, err
+}
+
+// adapt converts value, which is a pointer of the Cart, to a *T,
+// i.e. value itself if it is a *T or a pointer to the interface T holding value.
+func adapt[T any](value any) (*T, bool) {
+	if exact, ok := value.(*T); ok {
+		return exact, true
+	}
+
+	if iface, ok := value.(T); ok {
+		return &iface, true
+	}
+
+	return nil, false
+}
+
+// adaptRequest converts the *Request of the Cart to a *Request[T], see adapt
+func adaptRequest[T any](request any) (*Request[T], bool) {
+	if exact, ok := request.(*Request[T]); ok {
+		return exact, true
+	}
+
+	original := request.(AnyRequest)
+
+	body, ok := adapt[T](original.AnyBody())
+	if !ok {
+		return nil, false
+	}
+
+	return wrapToBodyRequest[T](original.HTTPRequest(), body, original.Raw()), true
+}
+
+// matches checks if the (nil) pointer probe can be adapted to a *T
+func matches[T any](probe any) bool {
+	_, ok := adapt[T](probe)
+	return ok
+}
+
+// unwrap reverses adapt, returning the pointer of the Cart
+func unwrap[T any](probe any, value *T) any {
+	if value == nil {
+		return nil
+	}
+
+	if _, exact := probe.(*T); exact {
+		return value
+	}
+
+	return any(*value)
+}
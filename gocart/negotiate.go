@@ -0,0 +1,164 @@
+package gocart
+
+import (
+	"github.com/benni-tec/gocart/goflag"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Negotiator can be implemented by a Serializer to choose the actual Serializer depending on the http.Request.
+// The Cart uses this to pick the output Serializer based on the request's Accept header.
+type Negotiator[T any] interface {
+	Serializer[T]
+	Negotiate(request *http.Request) Serializer[T]
+}
+
+// NegotiatingSerializer combines multiple Serializers for the same type and selects one
+// depending on the Accept (response) or Content-Type (request) header.
+type NegotiatingSerializer[T any] struct {
+	serializers []Serializer[T]
+}
+
+// Negotiate Serializer selects one of the serializers depending on the request,
+// e.g. Negotiate(MustTemplate[T](...), Json[T]()) allows a Cart to serve a browser page as well as a JSON API.
+//
+// The first Serializer is used if the client does not specify what it accepts, or none of the Serializers is accepted.
+// In the latter case the response is not rejected with 406, since clients often omit types they can handle from the Accept header.
+// Carts using a NegotiatingSerializer set the Vary: Accept header.
+func Negotiate[T any](serializers ...Serializer[T]) Serializer[T] {
+	return &NegotiatingSerializer[T]{serializers: serializers}
+}
+
+func (n *NegotiatingSerializer[T]) Negotiate(request *http.Request) Serializer[T] {
+	accepted := parseAccept(request.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		return n.first()
+	}
+
+	for _, accept := range accepted {
+		for _, serializer := range n.serializers {
+			for _, typ := range serializer.Type().HttpType {
+				if matchMediaType(accept, typ) {
+					return serializer
+				}
+			}
+		}
+	}
+
+	return n.first()
+}
+
+func (n *NegotiatingSerializer[T]) Serialize(body *T, headers http.Header) ([]byte, error) {
+	return n.first().Serialize(body, headers)
+}
+
+func (n *NegotiatingSerializer[T]) Deserialize(data []byte, headers http.Header) (*T, error) {
+	contentType, _, _ := mime.ParseMediaType(headers.Get("Content-Type"))
+	for _, serializer := range n.serializers {
+		for _, typ := range serializer.Type().HttpType {
+			if matchMediaType(contentType, typ) {
+				return serializer.Deserialize(data, headers)
+			}
+		}
+	}
+
+	return n.first().Deserialize(data, headers)
+}
+
+func (n *NegotiatingSerializer[T]) Type() *goflag.Type {
+	typ := &goflag.Type{
+		GoType:   genericToType[T](),
+		HttpType: []string{},
+	}
+
+	for _, serializer := range n.serializers {
+		typ.HttpType = append(typ.HttpType, serializer.Type().HttpType...)
+	}
+
+	return typ
+}
+
+func (n *NegotiatingSerializer[T]) first() Serializer[T] {
+	if len(n.serializers) == 0 {
+		panic("gocart: Negotiate requires at least one serializer")
+	}
+
+	return n.serializers[0]
+}
+
+// negotiate returns the Serializer that should be used for request, the serializer may be nil!
+func negotiate[T any](serializer Serializer[T], request *http.Request) Serializer[T] {
+	if negotiator, ok := serializer.(Negotiator[T]); ok {
+		return negotiator.Negotiate(request)
+	}
+
+	return serializer
+}
+
+// acceptedType returns the first of types accepted by request, or the first one if none is
+func acceptedType(types []string, request *http.Request) string {
+	for _, accept := range parseAccept(request.Header.Get("Accept")) {
+		for _, typ := range types {
+			if matchMediaType(accept, typ) {
+				return typ
+			}
+		}
+	}
+
+	return types[0]
+}
+
+// parseAccept returns the media ranges of an Accept header ordered by their quality
+func parseAccept(header string) []string {
+	type mediaRange struct {
+		typ     string
+		quality float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{typ: typ, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	types := make([]string, len(ranges))
+	for i, r := range ranges {
+		types[i] = r.typ
+	}
+
+	return types
+}
+
+// matchMediaType checks if typ is matched by the media range accept, which may contain wildcards
+func matchMediaType(accept string, typ string) bool {
+	if accept == "*/*" || accept == typ {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(accept, "/*"); ok {
+		return strings.HasPrefix(typ, prefix+"/")
+	}
+
+	return false
+}
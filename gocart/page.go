@@ -0,0 +1,175 @@
+package gocart
+
+import (
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageLimit is used by the page helpers if no limit was requested, MaxPageLimit caps the requested limit.
+var (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// HeaderSetter can be implemented by an output to set response headers depending on the request,
+// it is called by the Cart before the output is serialized (e.g. Page sets its Link header).
+type HeaderSetter interface {
+	SetHeaders(header http.Header, request *http.Request)
+}
+
+// OffsetParams are the query parameters of an offset based list, they can be embedded into TInput.
+type OffsetParams struct {
+	Limit  int `query:"limit" minimum:"0" description:"Maximum number of items to return"`
+	Offset int `query:"offset" minimum:"0" description:"Number of items to skip"`
+}
+
+// CursorParams are the query parameters of a cursor based list, they can be embedded into TInput.
+type CursorParams struct {
+	Limit  int    `query:"limit" minimum:"0" description:"Maximum number of items to return"`
+	Cursor string `query:"cursor" description:"Cursor of the page to return, as returned in next or prev"`
+}
+
+// Page is a part of a list, Next and Prev are the offsets or cursors of the adjacent pages (if there are any).
+// When used as an output the Cart sets a Link header (RFC 8288) pointing to them.
+type Page[T any] struct {
+	Items []T    `json:"items" yaml:"items" xml:"items" description:"Items of this page"`
+	Total *int   `json:"total,omitempty" yaml:"total,omitempty" xml:"total,omitempty" description:"Total number of items, if known"`
+	Next  string `json:"next,omitempty" yaml:"next,omitempty" xml:"next,omitempty" description:"Offset or cursor of the next page"`
+	Prev  string `json:"prev,omitempty" yaml:"prev,omitempty" xml:"prev,omitempty" description:"Offset or cursor of the previous page"`
+
+	// param is the query parameter Next and Prev are used for in the Link header
+	param string
+}
+
+// PageOf returns the page of items (i.e. all items of the list) selected by params.
+func PageOf[T any](items []T, params OffsetParams) *Page[T] {
+	limit := pageLimit(params.Limit)
+	offset := max(params.Offset, 0)
+
+	total := len(items)
+	return PageWindow(items[min(offset, total):min(offset+limit, total)], total, params)
+}
+
+// PageWindow returns the page of items that have already been selected by params (e.g. by a database query) from total items.
+func PageWindow[T any](items []T, total int, params OffsetParams) *Page[T] {
+	limit := pageLimit(params.Limit)
+	offset := max(params.Offset, 0)
+
+	page := &Page[T]{
+		Items: items,
+		Total: &total,
+		param: "offset",
+	}
+
+	if offset+limit < total {
+		page.Next = strconv.Itoa(offset + limit)
+	}
+
+	if offset > 0 {
+		page.Prev = strconv.Itoa(max(offset-limit, 0))
+	}
+
+	return page
+}
+
+// PageSeq returns the page of seq selected by params, seq is only consumed as far as needed.
+// Since the total is unknown, Total is nil.
+func PageSeq[T any](seq iter.Seq[T], params OffsetParams) *Page[T] {
+	limit := pageLimit(params.Limit)
+	offset := max(params.Offset, 0)
+
+	items, more := take(seq, offset, limit)
+	page := &Page[T]{Items: items, param: "offset"}
+
+	if more {
+		page.Next = strconv.Itoa(offset + limit)
+	}
+
+	if offset > 0 {
+		page.Prev = strconv.Itoa(max(offset-limit, 0))
+	}
+
+	return page
+}
+
+// CursorPage returns the page of a cursor based list, seq has to start after params.Cursor.
+// The cursor function returns the cursor of an item, the one of the last item is used as Next.
+// Prev is left empty, since it cannot be derived from a forward iterator.
+func CursorPage[T any](seq iter.Seq[T], params CursorParams, cursor func(item T) string) *Page[T] {
+	items, more := take(seq, 0, pageLimit(params.Limit))
+	page := &Page[T]{Items: items, param: "cursor"}
+
+	if more && len(items) > 0 {
+		page.Next = cursor(items[len(items)-1])
+	}
+
+	return page
+}
+
+// WithParam sets the query parameter Next and Prev refer to in the Link header, it defaults to "cursor".
+func (page *Page[T]) WithParam(name string) *Page[T] {
+	page.param = name
+	return page
+}
+
+func (page *Page[T]) SetHeaders(header http.Header, request *http.Request) {
+	param := page.param
+	if param == "" {
+		param = "cursor"
+	}
+
+	var links []string
+	if page.Next != "" {
+		links = append(links, "<"+pageURL(request, param, page.Next)+`>; rel="next"`)
+	}
+
+	if page.Prev != "" {
+		links = append(links, "<"+pageURL(request, param, page.Prev)+`>; rel="prev"`)
+	}
+
+	if len(links) > 0 {
+		header.Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageURL(request *http.Request, param string, value string) string {
+	u := *request.URL
+	query := u.Query()
+	query.Set(param, value)
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+
+	return min(limit, MaxPageLimit)
+}
+
+// take skips offset items of seq and returns the next limit ones, and whether there are more
+func take[T any](seq iter.Seq[T], offset int, limit int) ([]T, bool) {
+	items := make([]T, 0, limit)
+	more := false
+
+	i := 0
+	for item := range seq {
+		if i < offset {
+			i++
+			continue
+		}
+
+		if len(items) == limit {
+			more = true
+			break
+		}
+
+		items = append(items, item)
+	}
+
+	return items, more
+}
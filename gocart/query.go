@@ -0,0 +1,517 @@
+package gocart
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"github.com/benni-tec/gocart/middleware"
+	"github.com/swaggest/jsonschema-go"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ParamsDecoder can be implemented by (a pointer to) a field of TInput to decode itself from the request, e.g. Query.
+// It is then skipped by the Decoders.
+type ParamsDecoder interface {
+	DecodeParams(request *http.Request) error
+}
+
+// Query is the sorting, filtering and sparse fieldset language of a list of T, it can be embedded into TInput:
+//
+//	?sort=-created,name&filter[status]=open&filter[price][gte]=10&fields=id,name
+//
+// Only (json) fields of T are accepted, sorting and filtering is limited to fields of primitive types.
+// Sort and Filter are handed to the handler, while Fields is applied by the Cart when the output is serialized as json.
+type Query[T any] struct {
+	Sort   Sort[T]   `query:"sort" collectionFormat:"csv" description:"Fields to sort by, prefixed with - for descending order"`
+	Filter Filter[T] `query:"filter" style:"deepObject" explode:"true" description:"Conditions the items have to match, e.g. filter[field]=value or filter[field][operator]=value"`
+	Fields Fields[T] `query:"fields" collectionFormat:"csv" description:"Fields to include in the response"`
+}
+
+// SortField is a field to sort by
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Sort is the sort order requested by the sort parameter
+type Sort[T any] []SortField
+
+// Operator of a Condition
+type Operator string
+
+const (
+	Eq  Operator = "eq"
+	Ne  Operator = "ne"
+	Lt  Operator = "lt"
+	Lte Operator = "lte"
+	Gt  Operator = "gt"
+	Gte Operator = "gte"
+	// In matches if the field equals one of the comma separated values
+	In Operator = "in"
+)
+
+// Operators are all operators accepted in a Filter
+var Operators = []Operator{Eq, Ne, Lt, Lte, Gt, Gte, In}
+
+// Condition is a single condition of a Filter, the value is already validated against the type of the field
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    string
+}
+
+// Filter are the conditions requested by the filter parameters, all of them have to match
+type Filter[T any] []Condition
+
+// Fields is the sparse fieldset requested by the fields parameter, if empty all fields are included
+type Fields[T any] []string
+
+func (query *Query[T]) DecodeParams(request *http.Request) error {
+	fields := queryFieldsOf[T]()
+	values := request.URL.Query()
+
+	query.Sort = nil
+	for _, name := range splitList(values.Get("sort")) {
+		field := SortField{Field: strings.TrimPrefix(name, "-"), Descending: strings.HasPrefix(name, "-")}
+		if f, ok := fields.byName[field.Field]; !ok || !f.primitive {
+			return middleware.Error(http.StatusBadRequest, fmt.Errorf("gocart: cannot sort by %q", field.Field))
+		}
+
+		query.Sort = append(query.Sort, field)
+	}
+
+	query.Fields = nil
+	for _, name := range splitList(values.Get("fields")) {
+		if _, ok := fields.byName[name]; !ok {
+			return middleware.Error(http.StatusBadRequest, fmt.Errorf("gocart: unknown field %q", name))
+		}
+
+		query.Fields = append(query.Fields, name)
+	}
+
+	query.Filter = nil
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		rest, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(rest, "]")
+		operator := Eq
+		if rest != "" {
+			op, ok := strings.CutPrefix(rest, "[")
+			if !ok || !strings.HasSuffix(op, "]") {
+				return middleware.Error(http.StatusBadRequest, fmt.Errorf("gocart: malformed filter %q", key))
+			}
+
+			operator = Operator(strings.TrimSuffix(op, "]"))
+		}
+
+		condition, err := fields.condition(name, operator, values.Get(key))
+		if err != nil {
+			return middleware.Error(http.StatusBadRequest, err)
+		}
+
+		query.Filter = append(query.Filter, condition)
+	}
+
+	return nil
+}
+
+// Apply filters and sorts items in memory, e.g. for small lists or tests
+func (query *Query[T]) Apply(items []T) []T {
+	items = slices.DeleteFunc(slices.Clone(items), func(item T) bool {
+		return !query.Filter.Match(item)
+	})
+
+	slices.SortStableFunc(items, query.Sort.Compare)
+	return items
+}
+
+func (query *Query[T]) fieldset() (reflect.Type, []string) {
+	return reflect.TypeFor[T](), query.Fields
+}
+
+// Compare compares two items according to the sort order, see slices.SortFunc
+func (sort Sort[T]) Compare(a T, b T) int {
+	fields := queryFieldsOf[T]()
+	for _, s := range sort {
+		field := fields.byName[s.Field]
+		c := comparePrimitives(reflect.ValueOf(a).FieldByIndex(field.index), reflect.ValueOf(b).FieldByIndex(field.index))
+		if s.Descending {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// Match returns whether the item matches all conditions
+func (filter Filter[T]) Match(item T) bool {
+	fields := queryFieldsOf[T]()
+	for _, condition := range filter {
+		field := fields.byName[condition.Field]
+		value := reflect.ValueOf(item).FieldByIndex(field.index)
+
+		var values []string
+		if condition.Operator == In {
+			values = splitList(condition.Value)
+		} else {
+			values = []string{condition.Value}
+		}
+
+		var compared []int
+		for _, str := range values {
+			other := reflect.New(field.typ).Elem()
+			_ = AssignPrimitive(other, str)
+			compared = append(compared, comparePrimitives(value, other))
+		}
+
+		var ok bool
+		switch condition.Operator {
+		case Eq, In:
+			ok = slices.Contains(compared, 0)
+		case Ne:
+			ok = compared[0] != 0
+		case Lt:
+			ok = compared[0] < 0
+		case Lte:
+			ok = compared[0] <= 0
+		case Gt:
+			ok = compared[0] > 0
+		case Gte:
+			ok = compared[0] >= 0
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (Sort[T]) JSONSchema() (jsonschema.Schema, error) {
+	var names []any
+	for _, field := range queryFieldsOf[T]().fields {
+		if field.primitive {
+			names = append(names, field.name, "-"+field.name)
+		}
+	}
+
+	items := jsonschema.Schema{}
+	items.WithType(jsonschema.String.Type()).WithEnum(names...)
+
+	schema := jsonschema.Schema{}
+	schema.WithType(jsonschema.Array.Type()).WithItems(*(&jsonschema.Items{}).WithSchemaOrBool(items.ToSchemaOrBool()))
+	return schema, nil
+}
+
+func (Filter[T]) JSONSchema() (jsonschema.Schema, error) {
+	var operators []any
+	for _, op := range Operators {
+		operators = append(operators, string(op))
+	}
+
+	value := jsonschema.Schema{}
+	value.WithType(jsonschema.String.Type())
+
+	names := jsonschema.Schema{}
+	names.WithType(jsonschema.String.Type()).WithEnum(operators...)
+
+	byOperator := jsonschema.Schema{}
+	byOperator.WithType(jsonschema.Object.Type()).
+		WithPropertyNames(names.ToSchemaOrBool()).
+		WithAdditionalProperties(value.ToSchemaOrBool())
+
+	condition := jsonschema.Schema{}
+	condition.WithOneOf(value.ToSchemaOrBool(), byOperator.ToSchemaOrBool())
+
+	schema := jsonschema.Schema{}
+	schema.WithType(jsonschema.Object.Type()).WithAdditionalProperties(jsonschema.SchemaOrBool{TypeBoolean: new(bool)})
+	for _, field := range queryFieldsOf[T]().fields {
+		if field.primitive {
+			schema.WithPropertiesItem(field.name, condition.ToSchemaOrBool())
+		}
+	}
+
+	return schema, nil
+}
+
+func (Fields[T]) JSONSchema() (jsonschema.Schema, error) {
+	var names []any
+	for _, field := range queryFieldsOf[T]().fields {
+		names = append(names, field.name)
+	}
+
+	items := jsonschema.Schema{}
+	items.WithType(jsonschema.String.Type()).WithEnum(names...)
+
+	schema := jsonschema.Schema{}
+	schema.WithType(jsonschema.Array.Type()).WithItems(*(&jsonschema.Items{}).WithSchemaOrBool(items.ToSchemaOrBool()))
+	return schema, nil
+}
+
+// +++ Fields +++
+
+// fieldSelector is implemented by a TInput embedding a Query, to apply the sparse fieldset to the output
+type fieldSelector interface {
+	fieldset() (reflect.Type, []string)
+}
+
+type queryField struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	primitive bool
+}
+
+type queryFields struct {
+	fields []queryField
+	byName map[string]queryField
+}
+
+var queryFieldCache sync.Map
+
+// queryFieldsOf returns the (json) fields of T, which are the allow-list of a Query
+func queryFieldsOf[T any]() *queryFields {
+	typ := reflect.TypeFor[T]()
+	if cached, ok := queryFieldCache.Load(typ); ok {
+		return cached.(*queryFields)
+	}
+
+	fields := &queryFields{byName: map[string]queryField{}}
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			name, ok := jsonName(field)
+			if !ok || field.Anonymous && field.Type.Kind() == reflect.Struct {
+				continue
+			}
+
+			f := queryField{name: name, index: field.Index, typ: field.Type, primitive: isPrimitive(field.Type)}
+			fields.fields = append(fields.fields, f)
+			fields.byName[name] = f
+		}
+	}
+
+	queryFieldCache.Store(typ, fields)
+	return fields
+}
+
+func (fields *queryFields) condition(name string, operator Operator, value string) (Condition, error) {
+	field, ok := fields.byName[name]
+	if !ok || !field.primitive {
+		return Condition{}, fmt.Errorf("gocart: cannot filter by %q", name)
+	}
+
+	if !slices.Contains(Operators, operator) {
+		return Condition{}, fmt.Errorf("gocart: unknown operator %q", operator)
+	}
+
+	values := []string{value}
+	if operator == In {
+		values = splitList(value)
+	}
+
+	for _, str := range values {
+		err := AssignPrimitive(reflect.New(field.typ).Elem(), str)
+		if err != nil {
+			return Condition{}, fmt.Errorf("gocart: invalid value %q for %q: %w", str, name, err)
+		}
+	}
+
+	return Condition{Field: name, Operator: operator, Value: value}, nil
+}
+
+// jsonName returns the name of the field when serialized as json, or false if it is not serialized
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+func isPrimitive(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func comparePrimitives(a reflect.Value, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return 0
+		} else if b.Bool() {
+			return -1
+		}
+
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	default:
+		return cmp.Compare(a.String(), b.String())
+	}
+}
+
+func splitList(str string) []string {
+	if str == "" {
+		return nil
+	}
+
+	return strings.Split(str, ",")
+}
+
+// selectFields removes all fields of objects of type target, that are not part of fields, from the json body.
+// The output type typ is used to find these objects within the body.
+// The remaining members are copied as they are, so their order, numbers and escaping are preserved.
+func selectFields(body []byte, typ reflect.Type, target reflect.Type, fields []string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	err := project(buffer, json.RawMessage(body), typ, target, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// project writes the json value raw of type typ to buffer, removing the fields of objects of type target
+func project(buffer *bytes.Buffer, raw json.RawMessage, typ reflect.Type, target reflect.Type, fields []string) error {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+
+	switch {
+	case raw[0] == '[' && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array):
+		var elements []json.RawMessage
+		err := json.Unmarshal(raw, &elements)
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteByte('[')
+		for i, element := range elements {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			err = project(buffer, element, typ.Elem(), target, fields)
+			if err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte(']')
+		return nil
+	case raw[0] == '{' && (typ == target || typ.Kind() == reflect.Map || typ.Kind() == reflect.Struct):
+		return projectObject(buffer, raw, typ, target, fields)
+	default:
+		buffer.Write(raw)
+		return nil
+	}
+}
+
+// projectObject writes the members of the json object raw in their order, see project
+func projectObject(buffer *bytes.Buffer, raw json.RawMessage, typ reflect.Type, target reflect.Type, fields []string) error {
+	members := map[string]reflect.Type{}
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			name, ok := jsonName(field)
+			if ok && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+				members[name] = field.Type
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	_, err := decoder.Token() // {
+	if err != nil {
+		return err
+	}
+
+	buffer.WriteByte('{')
+	first := true
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return err
+		}
+
+		if typ == target && !slices.Contains(fields, key) {
+			continue
+		}
+
+		if !first {
+			buffer.WriteByte(',')
+		}
+
+		first = false
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+
+		switch {
+		case typ == target:
+			buffer.Write(value)
+		case typ.Kind() == reflect.Map:
+			err = project(buffer, value, typ.Elem(), target, fields)
+		case members[key] != nil:
+			err = project(buffer, value, members[key], target, fields)
+		default:
+			buffer.Write(value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer.WriteByte('}')
+	return nil
+}
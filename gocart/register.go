@@ -0,0 +1,115 @@
+package gocart
+
+import (
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+)
+
+// Endpoint is a Cart registered with a gotrac.Router,
+// it allows to edit the information and options of both the Cart and the gotrac.Route in one chain.
+type Endpoint struct {
+	cart  Cart
+	route gotrac.Route
+}
+
+// Register registers the cart for method and pattern with router
+func Register(router gotrac.Router, method string, pattern string, cart Cart) *Endpoint {
+	return &Endpoint{cart: cart, route: router.Method(method, pattern, cart)}
+}
+
+// Method registers a new Cart (see IO) for method and pattern with router
+func Method[TInput any, TOutput any](router gotrac.Router, method string, pattern string, input Serializer[TInput], output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Register(router, method, pattern, IO(input, output, h))
+}
+
+// Get registers a new Cart without a request body (see O) for GET and pattern with router
+func Get[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodGet, pattern, nil, output, h)
+}
+
+// Delete registers a new Cart without a request body (see O) for DELETE and pattern with router
+func Delete[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodDelete, pattern, nil, output, h)
+}
+
+// Head registers a new Cart without a request body (see O) for HEAD and pattern with router
+func Head[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodHead, pattern, nil, output, h)
+}
+
+// Options registers a new Cart without a request body (see O) for OPTIONS and pattern with router
+func Options[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodOptions, pattern, nil, output, h)
+}
+
+// Connect registers a new Cart without a request body (see O) for CONNECT and pattern with router
+func Connect[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodConnect, pattern, nil, output, h)
+}
+
+// Trace registers a new Cart without a request body (see O) for TRACE and pattern with router
+func Trace[TInput any, TOutput any](router gotrac.Router, pattern string, output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodTrace, pattern, nil, output, h)
+}
+
+// Post registers a new Cart (see IO) for POST and pattern with router
+func Post[TInput any, TOutput any](router gotrac.Router, pattern string, input Serializer[TInput], output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodPost, pattern, input, output, h)
+}
+
+// Put registers a new Cart (see IO) for PUT and pattern with router
+func Put[TInput any, TOutput any](router gotrac.Router, pattern string, input Serializer[TInput], output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodPut, pattern, input, output, h)
+}
+
+// Patch registers a new Cart (see IO) for PATCH and pattern with router
+func Patch[TInput any, TOutput any](router gotrac.Router, pattern string, input Serializer[TInput], output Serializer[TOutput], h CartFunc[TInput, TOutput]) *Endpoint {
+	return Method(router, http.MethodPatch, pattern, input, output, h)
+}
+
+// Cart returns the registered Cart
+func (endpoint *Endpoint) Cart() Cart {
+	return endpoint.cart
+}
+
+// Route returns the gotrac.Route the Cart is registered as
+func (endpoint *Endpoint) Route() gotrac.Route {
+	return endpoint.route
+}
+
+// WithInfo edits the information of the route, e.g. its input/output types or responses
+func (endpoint *Endpoint) WithInfo(fn func(info *goflag.EndpointInformation)) *Endpoint {
+	endpoint.route.WithInfo(fn)
+	return endpoint
+}
+
+// WithCartInfo edits the information of the Cart
+func (endpoint *Endpoint) WithCartInfo(fn func(info *CartInformation)) *Endpoint {
+	endpoint.cart.WithInfo(fn)
+	return endpoint
+}
+
+// WithName names the route, see gotrac.Route
+func (endpoint *Endpoint) WithName(name string) *Endpoint {
+	endpoint.route.WithName(name)
+	return endpoint
+}
+
+// WithVerifier adds Verifiers to the Cart, see Cart.WithVerifier
+func (endpoint *Endpoint) WithVerifier(verifiers ...Verifier) *Endpoint {
+	endpoint.cart.WithVerifier(verifiers...)
+	return endpoint
+}
+
+// WithDigest adds a Content-Digest to the responses of the Cart, see Cart.WithDigest
+func (endpoint *Endpoint) WithDigest(algorithms ...DigestAlgorithm) *Endpoint {
+	endpoint.cart.WithDigest(algorithms...)
+	return endpoint
+}
+
+// WithInterceptor attaches Interceptors to the Cart, see Cart.WithInterceptor
+func (endpoint *Endpoint) WithInterceptor(interceptors ...Interceptor) *Endpoint {
+	endpoint.cart.WithInterceptor(interceptors...)
+	return endpoint
+}
@@ -9,12 +9,23 @@ import (
 type Request[TBody any] struct {
 	http.Request
 	body *TBody
+	raw  []byte
 }
 
-func wrapToBodyRequest[TBody any](request *http.Request, body *TBody) *Request[TBody] {
+// AnyRequest is implemented by every Request regardless of its body type, e.g. for an Interceptor of all Carts
+type AnyRequest interface {
+	// AnyBody returns the pointer to the deserialized body, i.e. the *TBody
+	AnyBody() any
+	// HTTPRequest returns the http.Request, changes made to it (e.g. replacing it using WithContext) are passed to the CartFunc
+	HTTPRequest() *http.Request
+	Raw() []byte
+}
+
+func wrapToBodyRequest[TBody any](request *http.Request, body *TBody, raw []byte) *Request[TBody] {
 	return &Request[TBody]{
 		Request: *request,
 		body:    body,
+		raw:     raw,
 	}
 }
 
@@ -22,3 +33,18 @@ func wrapToBodyRequest[TBody any](request *http.Request, body *TBody) *Request[T
 func (r *Request[TBody]) Body() *TBody {
 	return r.body
 }
+
+// Raw returns the raw bytes of the body as they were received, e.g. to check a custom signature.
+// This is nil if the Cart did not read the body, i.e. it has no input Serializer or the Serializer reads the request itself,
+// unless the Cart has Verifiers.
+func (r *Request[TBody]) Raw() []byte {
+	return r.raw
+}
+
+func (r *Request[TBody]) AnyBody() any {
+	return r.body
+}
+
+func (r *Request[TBody]) HTTPRequest() *http.Request {
+	return &r.Request
+}
@@ -0,0 +1,265 @@
+package gocart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"github.com/benni-tec/gocart/middleware"
+	"github.com/swaggest/jsonschema-go"
+	"go/token"
+	"net/http"
+	"path"
+	"reflect"
+)
+
+// ErrNotFound can be returned by a Repository if an item does not exist, the Resource then responds with 404.
+var ErrNotFound = errors.New("gocart: not found")
+
+// Repository is the storage of a Resource.
+type Repository[T any, ID any] interface {
+	// List returns the items within the limit and offset and the total number of items
+	List(ctx context.Context, params ListParams) ([]T, int, error)
+	// Get returns the item with the id, or nil (or ErrNotFound) if it does not exist
+	Get(ctx context.Context, id ID) (*T, error)
+	// Create stores a new item and returns its id
+	Create(ctx context.Context, item *T) (ID, error)
+	// Update replaces the item with the id, or returns ErrNotFound if it does not exist
+	Update(ctx context.Context, id ID, item *T) error
+	// Delete removes the item with the id, or returns ErrNotFound if it does not exist
+	Delete(ctx context.Context, id ID) error
+}
+
+// ListParams are the query parameters of a list operation, the limit is already capped (see DefaultPageLimit and MaxPageLimit)
+type ListParams = OffsetParams
+
+// Operation is one of the standard operations of a Resource
+type Operation string
+
+const (
+	OpList   Operation = "list"
+	OpGet    Operation = "get"
+	OpCreate Operation = "create"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+)
+
+// Resource mounts the standard list, get, create, update and delete routes for items of type T
+// identified by an ID, which has to be a primitive (see AssignPrimitive).
+// T has to be an exported struct, since it is embedded into the documented input of the update operation.
+type Resource[T any, ID any] struct {
+	name       string
+	repository Repository[T, ID]
+
+	overrides map[Operation]Cart
+	disabled  map[Operation]bool
+}
+
+// NewResource creates a Resource called name (the name of the controller in the documentation) that is backed by repository.
+func NewResource[T any, ID any](name string, repository Repository[T, ID]) *Resource[T, ID] {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct || !token.IsExported(typ.Name()) {
+		panic(fmt.Sprintf("gocart: the type %s of a Resource has to be an exported struct", typ))
+	}
+
+	return &Resource[T, ID]{
+		name:       name,
+		repository: repository,
+		overrides:  map[Operation]Cart{},
+		disabled:   map[Operation]bool{},
+	}
+}
+
+// Override replaces the Cart of an operation.
+func (r *Resource[T, ID]) Override(op Operation, cart Cart) *Resource[T, ID] {
+	r.overrides[op] = cart
+	return r
+}
+
+// Disable removes operations, their routes are not registered.
+func (r *Resource[T, ID]) Disable(ops ...Operation) *Resource[T, ID] {
+	for _, op := range ops {
+		r.disabled[op] = true
+	}
+
+	return r
+}
+
+// Mount registers the routes as a controller named after the Resource beneath pattern on the router.
+func (r *Resource[T, ID]) Mount(router gotrac.Router, pattern string) goflag.ControllerFlag {
+	sub := gotrac.NewRouter()
+
+	routes := []struct {
+		op      Operation
+		method  string
+		pattern string
+		cart    func() Cart
+	}{
+		{OpList, http.MethodGet, "/", r.list},
+		{OpCreate, http.MethodPost, "/", r.create},
+		{OpGet, http.MethodGet, "/{id}", r.get},
+		{OpUpdate, http.MethodPut, "/{id}", r.update},
+		{OpDelete, http.MethodDelete, "/{id}", r.delete},
+	}
+
+	for _, route := range routes {
+		if r.disabled[route.op] {
+			continue
+		}
+
+		cart, ok := r.overrides[route.op]
+		if !ok {
+			cart = route.cart()
+		}
+
+		sub.Method(route.method, route.pattern, cart)
+	}
+
+	controller := gotrac.WithName(r.name, sub)
+	router.Mount(pattern, controller)
+	return controller
+}
+
+// +++ Operations +++
+
+type resourceID[ID any] struct {
+	ID resourceKey[ID] `path:"id"`
+}
+
+// resourceKey is the id path parameter, which is documented as an ID
+type resourceKey[ID any] struct {
+	value ID
+}
+
+// DecodeParams parses the id like the update operation does, so an invalid id is answered with 400 by all operations
+func (k *resourceKey[ID]) DecodeParams(request *http.Request) error {
+	id, err := parseID[ID](request.PathValue("id"))
+	if err != nil {
+		return middleware.Error(http.StatusBadRequest, err)
+	}
+
+	k.value = id
+	return nil
+}
+
+func (resourceKey[ID]) JSONSchema() (jsonschema.Schema, error) {
+	reflector := jsonschema.Reflector{}
+	return reflector.Reflect(*new(ID))
+}
+
+func (r *Resource[T, ID]) list() Cart {
+	return IO(nil, Json[Page[T]](), func(request *Request[ListParams], _ HeaderWriter) (*Page[T], error) {
+		params := *request.Body()
+		params.Limit = pageLimit(params.Limit)
+
+		items, total, err := r.repository.List(request.Context(), params)
+		if err != nil {
+			return nil, err
+		}
+
+		return PageWindow(items, total, params), nil
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("List " + r.name).
+			WithDescription("Returns the page of " + r.name + " within the limit and offset, including the total number.")
+	})
+}
+
+func (r *Resource[T, ID]) get() Cart {
+	return IO(nil, Json[T](), func(request *Request[resourceID[ID]], _ HeaderWriter) (*T, error) {
+		item, err := r.repository.Get(request.Context(), request.Body().ID.value)
+		if err == nil && item == nil {
+			err = ErrNotFound
+		}
+
+		if err != nil {
+			return nil, resourceError(err)
+		}
+
+		return item, nil
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("Get " + r.name).
+			WithDescription("Returns the item with the id, responds with 404 if it does not exist.")
+	})
+}
+
+func (r *Resource[T, ID]) create() Cart {
+	created := Created(Json[T]())
+
+	return IO(Json[T](), Responses(created), func(request *Request[T], _ HeaderWriter) (*Result, error) {
+		id, err := r.repository.Create(request.Context(), request.Body())
+		if err != nil {
+			return nil, resourceError(err)
+		}
+
+		return created.At(path.Join(request.URL.Path, fmt.Sprint(id)), request.Body()), nil
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("Create " + r.name).
+			WithDescription("Creates a new item and responds with 201, the Location header contains its URL.")
+	})
+}
+
+func (r *Resource[T, ID]) update() Cart {
+	return IO(&resourceSerializer[T, ID]{Serializer: Json[T]()}, Json[T](), func(request *Request[T], _ HeaderWriter) (*T, error) {
+		id, err := parseID[ID](request.PathValue("id"))
+		if err != nil {
+			return nil, middleware.Error(http.StatusBadRequest, err)
+		}
+
+		err = r.repository.Update(request.Context(), id, request.Body())
+		if err != nil {
+			return nil, resourceError(err)
+		}
+
+		return request.Body(), nil
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("Update " + r.name).
+			WithDescription("Replaces the item with the id, responds with 404 if it does not exist.")
+	})
+}
+
+func (r *Resource[T, ID]) delete() Cart {
+	return A(func(request *Request[resourceID[ID]], _ HeaderWriter) (*struct{}, error) {
+		err := r.repository.Delete(request.Context(), request.Body().ID.value)
+		if err != nil {
+			return nil, resourceError(err)
+		}
+
+		return nil, nil
+	}).WithInfo(func(info *CartInformation) {
+		info.WithSummary("Delete " + r.name).
+			WithDescription("Deletes the item with the id and responds with 204, or 404 if it does not exist.")
+	})
+}
+
+// resourceSerializer documents the body T together with the id path parameter
+type resourceSerializer[T any, ID any] struct {
+	Serializer[T]
+}
+
+func (s *resourceSerializer[T, ID]) Type() *goflag.Type {
+	typ := s.Serializer.Type()
+	body := reflect.TypeFor[T]()
+
+	return &goflag.Type{
+		GoType: reflect.StructOf([]reflect.StructField{
+			{Name: "ID", Type: reflect.TypeFor[ID](), Tag: `path:"id" json:"-"`},
+			{Name: body.Name(), Type: body, Anonymous: true},
+		}),
+		HttpType: typ.HttpType,
+	}
+}
+
+func parseID[ID any](str string) (ID, error) {
+	var id ID
+	err := AssignPrimitive(reflect.ValueOf(&id).Elem(), str)
+	return id, err
+}
+
+func resourceError(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return middleware.Error(http.StatusNotFound, err)
+	}
+
+	return err
+}
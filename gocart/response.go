@@ -0,0 +1,202 @@
+package gocart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"slices"
+)
+
+// ResponseDescriber can be implemented by an output Serializer that documents several responses,
+// these are then used as goflag.EndpointInformation.Responses instead of the Output.
+type ResponseDescriber interface {
+	Responses() []goflag.Response
+}
+
+// AnyResponse is a Response of any type, see Responses
+type AnyResponse interface {
+	response() goflag.Response
+	write(w http.ResponseWriter, r *http.Request, body any) error
+}
+
+// Response is a typed response with a specific status code, a Result is created using Of.
+type Response[T any] struct {
+	status      int
+	description string
+	headers     map[string]string
+	serializer  Serializer[T]
+}
+
+// Status declares a Response with the status code, whose body is serialized using serializer.
+// If serializer is nil the response has no body, but the fields of T can still set headers.
+func Status[T any](status int, serializer Serializer[T]) *Response[T] {
+	return &Response[T]{status: status, serializer: serializer}
+}
+
+// WithDescription sets the description of the response in the documentation
+func (response *Response[T]) WithDescription(description string) *Response[T] {
+	response.description = description
+	return response
+}
+
+// WithHeader documents a header that is set using Result.WithHeader (headers declared by fields of T are documented anyway)
+func (response *Response[T]) WithHeader(name string, description string) *Response[T] {
+	if response.headers == nil {
+		response.headers = map[string]string{}
+	}
+
+	response.headers[name] = description
+	return response
+}
+
+// Of returns a Result responding with this Response and body
+func (response *Response[T]) Of(body *T) *Result {
+	return &Result{response: response, body: body, header: http.Header{}}
+}
+
+// At returns a Result responding with this Response and body, and the Location header set to location
+func (response *Response[T]) At(location string, body *T) *Result {
+	return response.Of(body).WithHeader("Location", location)
+}
+
+// AtRoute is like At, but generates the location from the name of a route and its params, see gotrac.URL
+func (response *Response[T]) AtRoute(ctx context.Context, name string, params any, body *T) (*Result, error) {
+	location, err := gotrac.URL(ctx, name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.At(location, body), nil
+}
+
+// Redirect declares a redirect Response (301, 302, 303, 307 or 308) without a body, use At to set the Location.
+func Redirect(status int) *Response[struct{}] {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("gocart: %d is not a redirect status", status))
+	}
+
+	return Status[struct{}](status, nil).
+		WithDescription(http.StatusText(status)).
+		WithHeader("Location", "URL to redirect to")
+}
+
+// Created declares a 201 Response whose body is serialized using serializer, use At to set the Location.
+func Created[T any](serializer Serializer[T]) *Response[T] {
+	return Status(http.StatusCreated, serializer).
+		WithDescription(http.StatusText(http.StatusCreated)).
+		WithHeader("Location", "URL of the created resource")
+}
+
+func (response *Response[T]) response() goflag.Response {
+	typ := gotrac.None[T]()
+	if response.serializer != nil {
+		typ = response.serializer.Type()
+	}
+
+	return goflag.Response{Status: response.status, Description: response.description, Output: typ, Headers: response.headers}
+}
+
+func (response *Response[T]) write(w http.ResponseWriter, r *http.Request, body any) error {
+	output, _ := body.(*T)
+
+	err := encodeHeaders(w, r, output)
+	if err != nil {
+		return err
+	}
+
+	serializer := negotiate(response.serializer, r)
+	if serializer == nil || output == nil {
+		w.WriteHeader(response.status)
+		return nil
+	}
+
+	setContentType(w.Header(), serializer, r)
+	if writer, ok := serializer.(ResponseSerializer[T]); ok {
+		return writer.WriteResponse(w, r, output)
+	}
+
+	data, err := serializer.Serialize(output, w.Header())
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(response.status)
+	_, err = w.Write(data)
+	return err
+}
+
+// Result is the output of a Cart with several responses, it is created using Response.Of
+type Result struct {
+	response AnyResponse
+	body     any
+	header   http.Header
+}
+
+// WithHeader sets a header of the response, e.g. one declared by Response.WithHeader
+func (result *Result) WithHeader(key string, value string) *Result {
+	result.header.Set(key, value)
+	return result
+}
+
+// ResultSerializer writes the Result using the Response it was created with, see Responses
+type ResultSerializer struct {
+	responses []AnyResponse
+}
+
+// Responses returns the output Serializer of a Cart that can respond with any of the responses,
+// the CartFunc picks one by returning a Result created by Response.Of:
+//
+//	ok := gocart.Status(http.StatusOK, gocart.Json[User]())
+//	accepted := gocart.Status(http.StatusAccepted, gocart.Json[Job]()).WithDescription("The user is being created")
+//
+//	gocart.IO(gocart.Json[NewUser](), gocart.Responses(ok, accepted), func(...) (*gocart.Result, error) {
+//		return accepted.Of(&job), nil
+//	})
+//
+// All responses are documented, returning a Result of a Response that was not passed here is an error.
+func Responses(responses ...AnyResponse) *ResultSerializer {
+	return &ResultSerializer{responses: responses}
+}
+
+func (serializer *ResultSerializer) Serialize(*Result, http.Header) ([]byte, error) {
+	return nil, errors.New("gocart: a Result can only be written as a response")
+}
+
+func (serializer *ResultSerializer) Deserialize([]byte, http.Header) (*Result, error) {
+	return nil, errors.New("gocart: a Result cannot be deserialized")
+}
+
+func (serializer *ResultSerializer) Type() *goflag.Type {
+	return gotrac.None[Result]()
+}
+
+func (serializer *ResultSerializer) Responses() []goflag.Response {
+	var responses []goflag.Response
+	for _, response := range serializer.responses {
+		responses = append(responses, response.response())
+	}
+
+	return responses
+}
+
+func (serializer *ResultSerializer) WriteResponse(w http.ResponseWriter, r *http.Request, result *Result) error {
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	if !slices.Contains(serializer.responses, result.response) {
+		return fmt.Errorf("gocart: the response %d was not declared for this cart", result.response.response().Status)
+	}
+
+	for key, values := range result.header {
+		w.Header()[key] = values
+	}
+
+	return result.response.write(w, r, result.body)
+}
@@ -18,6 +18,14 @@ type Serializer[T any] interface {
 	Type() *goflag.Type
 }
 
+// ResponseSerializer can be implemented by a Serializer to write the response itself,
+// instead of returning the serialized body, e.g. to stream the body or to answer range requests.
+// If implemented the Cart calls WriteResponse instead of Serialize.
+type ResponseSerializer[T any] interface {
+	Serializer[T]
+	WriteResponse(w http.ResponseWriter, r *http.Request, body *T) error
+}
+
 // +++ JSON, YAML +++
 
 // MarshalSerializer implements the Serializer interface using the go-convention marshal/unmarshal functions.
@@ -56,19 +64,19 @@ func Xml[T any]() Serializer[T] {
 }
 
 func (j *MarshalSerializer[T]) Serialize(body *T, headers http.Header) ([]byte, error) {
-	return json.Marshal(body)
+	return j.marshal(body)
 }
 
 func (j *MarshalSerializer[T]) Deserialize(data []byte, headers http.Header) (*T, error) {
 	value := new(T)
-	err := json.Unmarshal(data, value)
+	err := j.unmarshal(data, value)
 	return value, err
 }
 
 func (j *MarshalSerializer[T]) Type() *goflag.Type {
 	return &goflag.Type{
 		GoType:   genericToType[T](),
-		HttpType: []string{"application/json"},
+		HttpType: j.mimeTypes,
 	}
 }
 
@@ -0,0 +1,62 @@
+package gocart
+
+import (
+	"context"
+)
+
+// Service is used to define a Cart from a transport-agnostic function, e.g. a method of your service layer.
+// The deserialized input is passed by value together with the request's context and the returned value is serialized.
+//
+// The input Serializer can be nil, in which case only the meta-fields of TInput are populated.
+func Service[TInput any, TOutput any](input Serializer[TInput], output Serializer[TOutput], fn func(ctx context.Context, input TInput) (TOutput, error)) Cart {
+	return IO(input, output, func(request *Request[TInput], _ HeaderWriter) (*TOutput, error) {
+		result, err := fn(request.Context(), *request.Body())
+		if err != nil {
+			return nil, err
+		}
+
+		return &result, nil
+	})
+}
+
+// ServicePtr is like Service, but for functions returning a pointer.
+func ServicePtr[TInput any, TOutput any](input Serializer[TInput], output Serializer[TOutput], fn func(ctx context.Context, input TInput) (*TOutput, error)) Cart {
+	return IO(input, output, func(request *Request[TInput], _ HeaderWriter) (*TOutput, error) {
+		return fn(request.Context(), *request.Body())
+	})
+}
+
+// Consumer is used to define a Cart from a transport-agnostic function that does not return anything.
+// The Cart responds with 204 No Content.
+func Consumer[TInput any](input Serializer[TInput], fn func(ctx context.Context, input TInput) error) Cart {
+	return I(input, func(request *Request[TInput], _ HeaderWriter) (*struct{}, error) {
+		return nil, fn(request.Context(), *request.Body())
+	})
+}
+
+// Producer is used to define a Cart from a transport-agnostic function that does not take any input.
+func Producer[TOutput any](output Serializer[TOutput], fn func(ctx context.Context) (TOutput, error)) Cart {
+	return O(output, func(request *Request[struct{}], _ HeaderWriter) (*TOutput, error) {
+		result, err := fn(request.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		return &result, nil
+	})
+}
+
+// ProducerPtr is like Producer, but for functions returning a pointer.
+func ProducerPtr[TOutput any](output Serializer[TOutput], fn func(ctx context.Context) (*TOutput, error)) Cart {
+	return O(output, func(request *Request[struct{}], _ HeaderWriter) (*TOutput, error) {
+		return fn(request.Context())
+	})
+}
+
+// Action is used to define a Cart from a transport-agnostic function that neither takes input nor returns anything.
+// The Cart responds with 204 No Content.
+func Action(fn func(ctx context.Context) error) Cart {
+	return A(func(request *Request[struct{}], _ HeaderWriter) (*struct{}, error) {
+		return nil, fn(request.Context())
+	})
+}
@@ -0,0 +1,153 @@
+package gocart
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/benni-tec/gocart/middleware"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSignature is returned when a request is not signed correctly.
+var ErrSignature = errors.New("gocart: invalid request signature")
+
+// SignatureScheme describes how a HMAC signature is sent by the client and what it is computed over.
+// Schemes for common webhook providers are GitHubSignature, StripeSignature and SlackSignature.
+type SignatureScheme struct {
+	// Hash used for the HMAC, e.g. sha256.New
+	Hash func() hash.Hash
+	// Extract returns the timestamp (empty if not signed) and the candidate signatures of the request
+	Extract func(request *http.Request) (timestamp string, signatures [][]byte, err error)
+	// Payload returns the signed data, if nil the body is signed
+	Payload func(timestamp string, body []byte) []byte
+}
+
+// SecretFunc returns the secrets a request may be signed with, multiple secrets allow for key rotation.
+// The request can be used to look up the secret, e.g. by a header identifying the client.
+type SecretFunc func(request *http.Request) ([][]byte, error)
+
+// Secrets returns a SecretFunc that always returns the given secrets.
+func Secrets(secrets ...[]byte) SecretFunc {
+	return func(_ *http.Request) ([][]byte, error) {
+		return secrets, nil
+	}
+}
+
+// HeaderSignature is a SignatureScheme where the hex encoded HMAC-SHA256 of the body is sent in header, after the prefix.
+func HeaderSignature(header string, prefix string) SignatureScheme {
+	return SignatureScheme{
+		Hash: sha256.New,
+		Extract: func(request *http.Request) (string, [][]byte, error) {
+			value, ok := strings.CutPrefix(request.Header.Get(header), prefix)
+			if !ok {
+				return "", nil, ErrSignature
+			}
+
+			signature, err := hex.DecodeString(value)
+			return "", [][]byte{signature}, err
+		},
+	}
+}
+
+// GitHubSignature is the SignatureScheme used by GitHub webhooks (X-Hub-Signature-256).
+var GitHubSignature = HeaderSignature("X-Hub-Signature-256", "sha256=")
+
+// StripeSignature is the SignatureScheme used by Stripe webhooks (Stripe-Signature).
+var StripeSignature = SignatureScheme{
+	Hash: sha256.New,
+	Extract: func(request *http.Request) (string, [][]byte, error) {
+		var timestamp string
+		var signatures [][]byte
+
+		for _, part := range strings.Split(request.Header.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signature, err := hex.DecodeString(value)
+				if err != nil {
+					return "", nil, err
+				}
+
+				signatures = append(signatures, signature)
+			}
+		}
+
+		return timestamp, signatures, nil
+	},
+	Payload: func(timestamp string, body []byte) []byte {
+		return append([]byte(timestamp+"."), body...)
+	},
+}
+
+// SlackSignature is the SignatureScheme used by Slack (X-Slack-Signature and X-Slack-Request-Timestamp).
+var SlackSignature = SignatureScheme{
+	Hash: sha256.New,
+	Extract: func(request *http.Request) (string, [][]byte, error) {
+		value, ok := strings.CutPrefix(request.Header.Get("X-Slack-Signature"), "v0=")
+		if !ok {
+			return "", nil, ErrSignature
+		}
+
+		signature, err := hex.DecodeString(value)
+		return request.Header.Get("X-Slack-Request-Timestamp"), [][]byte{signature}, err
+	},
+	Payload: func(timestamp string, body []byte) []byte {
+		return append([]byte("v0:"+timestamp+":"), body...)
+	},
+}
+
+// Signature returns a Verifier that checks the HMAC signature of the raw body using the scheme.
+// If tolerance is greater than 0 the request has to contain a timestamp (unix seconds) within this window,
+// this prevents replaying old requests.
+//
+// Requests that fail the check are rejected with 401 before the handler is called.
+func Signature(scheme SignatureScheme, secrets SecretFunc, tolerance time.Duration) Verifier {
+	return VerifierFunc(func(request *http.Request, body []byte) error {
+		timestamp, signatures, err := scheme.Extract(request)
+		if err != nil || len(signatures) == 0 {
+			return middleware.Error(http.StatusUnauthorized, ErrSignature)
+		}
+
+		if tolerance > 0 {
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return middleware.Error(http.StatusUnauthorized, ErrSignature)
+			}
+
+			if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+				return middleware.Error(http.StatusUnauthorized, ErrSignature)
+			}
+		}
+
+		keys, err := secrets(request)
+		if err != nil {
+			return err
+		}
+
+		payload := body
+		if scheme.Payload != nil {
+			payload = scheme.Payload(timestamp, body)
+		}
+
+		for _, key := range keys {
+			mac := hmac.New(scheme.Hash, key)
+			mac.Write(payload)
+			expected := mac.Sum(nil)
+
+			for _, signature := range signatures {
+				if hmac.Equal(expected, signature) {
+					return nil
+				}
+			}
+		}
+
+		return middleware.Error(http.StatusUnauthorized, ErrSignature)
+	})
+}
@@ -0,0 +1,75 @@
+package gocart
+
+import (
+	"bytes"
+	"errors"
+	"github.com/benni-tec/gocart/goflag"
+	"html/template"
+	"io/fs"
+	"net/http"
+)
+
+// TemplateSerializer renders the body using a named html/template.
+// It can only be used to serialize a response, deserializing will always fail!
+type TemplateSerializer[T any] struct {
+	template *template.Template
+	name     string
+}
+
+// Template Serializer renders the response by executing the template called name.
+// The templates are parsed from fsys using the given patterns (see template.ParseFS),
+// so layouts can be provided by simply including them in the patterns.
+//
+// All templates are parsed immediately, thus parse errors are returned when the Cart is defined and not when it is called.
+func Template[T any](fsys fs.FS, name string, patterns ...string) (Serializer[T], error) {
+	tmpl, err := template.New(name).ParseFS(fsys, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return TemplateOf[T](tmpl, name)
+}
+
+// TemplateOf Serializer renders the response by executing the template called name of an already parsed template.
+// This can be used if the template needs further configuration, like custom functions.
+func TemplateOf[T any](tmpl *template.Template, name string) (Serializer[T], error) {
+	if tmpl.Lookup(name) == nil {
+		return nil, errors.New("gocart: template " + name + " is not defined")
+	}
+
+	return &TemplateSerializer[T]{
+		template: tmpl,
+		name:     name,
+	}, nil
+}
+
+// MustTemplate is like Template but panics if the templates can not be parsed.
+func MustTemplate[T any](fsys fs.FS, name string, patterns ...string) Serializer[T] {
+	serializer, err := Template[T](fsys, name, patterns...)
+	if err != nil {
+		panic(err)
+	}
+
+	return serializer
+}
+
+func (s *TemplateSerializer[T]) Serialize(body *T, headers http.Header) ([]byte, error) {
+	var buf bytes.Buffer
+	err := s.template.ExecuteTemplate(&buf, s.name, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *TemplateSerializer[T]) Deserialize(data []byte, headers http.Header) (*T, error) {
+	return nil, errors.New("gocart: a html template can not be deserialized")
+}
+
+func (s *TemplateSerializer[T]) Type() *goflag.Type {
+	return &goflag.Type{
+		GoType:   genericToType[T](),
+		HttpType: []string{"text/html"},
+	}
+}
@@ -0,0 +1,227 @@
+package gocart
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/middleware"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+)
+
+// ErrTooLarge is returned when a request body exceeds the maximum size of a Stream or Spool serializer.
+var ErrTooLarge = errors.New("gocart: request body too large")
+
+// RequestSerializer can be implemented by a Serializer to read the request body itself,
+// instead of the Cart reading the whole body into memory.
+// If implemented the Cart calls ReadRequest instead of Deserialize.
+type RequestSerializer[T any] interface {
+	Serializer[T]
+	ReadRequest(r *http.Request) (*T, error)
+}
+
+// Upload is a request body that is read by the CartFunc itself instead of being buffered in memory.
+// Reading more than the maximum size of the Stream serializer returns a StatusError wrapping ErrTooLarge,
+// a body that is shorter than the declared Content-Length returns a StatusError with 400.
+type Upload struct {
+	io.Reader
+	// Length as declared by the Content-Length header, -1 if unknown
+	Length      int64
+	ContentType string
+}
+
+// SpooledUpload is a request body that has been written to a temporary file before calling the CartFunc.
+// The file is closed and removed once the CartFunc returns.
+type SpooledUpload struct {
+	*os.File
+	// Length is the actual number of bytes written to the file
+	Length int64
+	// Checksum is the SHA-256 hash of the body
+	Checksum    []byte
+	ContentType string
+}
+
+// Close closes and removes the temporary file.
+func (u *SpooledUpload) Close() error {
+	return errors.Join(u.File.Close(), os.Remove(u.File.Name()))
+}
+
+type uploadSerializer struct {
+	maxSize     int64
+	contentType []string
+}
+
+// UploadSerializer passes the body to the CartFunc as an io.Reader without buffering it.
+type UploadSerializer struct {
+	uploadSerializer
+}
+
+// Stream Serializer passes the body as an Upload to the CartFunc, so it can be read without buffering it in memory.
+// Requests that declare a Content-Length larger than maxSize are rejected before the body is read,
+// therefore clients sending "Expect: 100-continue" will not send the body at all.
+//
+// A maxSize <= 0 means the size is not limited. The contentType defaults to application/octet-stream!
+func Stream(maxSize int64, contentType ...string) Serializer[Upload] {
+	return &UploadSerializer{uploadSerializer: newUploadSerializer(maxSize, contentType)}
+}
+
+func (s *UploadSerializer) ReadRequest(r *http.Request) (*Upload, error) {
+	reader, err := s.open(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Upload{
+		Reader:      reader,
+		Length:      r.ContentLength,
+		ContentType: r.Header.Get("Content-Type"),
+	}, nil
+}
+
+func (s *UploadSerializer) Serialize(body *Upload, headers http.Header) ([]byte, error) {
+	if body == nil || body.Reader == nil {
+		return nil, nil
+	}
+
+	return io.ReadAll(body)
+}
+
+func (s *UploadSerializer) Deserialize(data []byte, headers http.Header) (*Upload, error) {
+	return nil, errors.New("gocart: an upload can only be read from a request")
+}
+
+// SpoolSerializer writes the body to a temporary file before calling the CartFunc.
+type SpoolSerializer struct {
+	uploadSerializer
+	dir string
+}
+
+// Spool Serializer writes the body to a temporary file in dir (or os.TempDir if empty) and passes it as a SpooledUpload,
+// including the length and SHA-256 checksum, to the CartFunc.
+// A body that is shorter than the declared Content-Length is rejected with 400.
+//
+// The same size restrictions as for Stream apply.
+func Spool(maxSize int64, dir string, contentType ...string) Serializer[SpooledUpload] {
+	return &SpoolSerializer{uploadSerializer: newUploadSerializer(maxSize, contentType), dir: dir}
+}
+
+func (s *SpoolSerializer) ReadRequest(r *http.Request) (*SpooledUpload, error) {
+	reader, err := s.open(r)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(s.dir, "gocart-upload-*")
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &SpooledUpload{
+		File:        file,
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	checksum := sha256.New()
+	upload.Length, err = io.Copy(io.MultiWriter(file, checksum), reader)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = upload.Close()
+		return nil, err
+	}
+
+	upload.Checksum = checksum.Sum(nil)
+	return upload, nil
+}
+
+func (s *SpoolSerializer) Serialize(body *SpooledUpload, headers http.Header) ([]byte, error) {
+	if body == nil || body.File == nil {
+		return nil, nil
+	}
+
+	return io.ReadAll(body)
+}
+
+func (s *SpoolSerializer) Deserialize(data []byte, headers http.Header) (*SpooledUpload, error) {
+	return nil, errors.New("gocart: a spooled upload can only be read from a request")
+}
+
+func newUploadSerializer(maxSize int64, contentType []string) uploadSerializer {
+	if len(contentType) == 0 {
+		contentType = []string{"application/octet-stream"}
+	}
+
+	return uploadSerializer{maxSize: maxSize, contentType: contentType}
+}
+
+// open validates the declared Content-Length and returns the size-limited body, which fails if it does not match the Content-Length
+func (s *uploadSerializer) open(r *http.Request) (io.Reader, error) {
+	var reader io.Reader = r.Body
+	if r.ContentLength >= 0 {
+		reader = &lengthReader{reader: reader, expected: r.ContentLength}
+	}
+
+	if s.maxSize <= 0 {
+		return reader, nil
+	}
+
+	if r.ContentLength > s.maxSize {
+		return nil, middleware.Error(http.StatusRequestEntityTooLarge, ErrTooLarge)
+	}
+
+	return &limitedReader{reader: reader, remaining: s.maxSize}, nil
+}
+
+func (s *uploadSerializer) Type() *goflag.Type {
+	return &goflag.Type{
+		GoType:   reflect.TypeOf([]byte{}),
+		HttpType: s.contentType,
+	}
+}
+
+// limitedReader fails with ErrTooLarge once more than the remaining bytes are read
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, middleware.Error(http.StatusRequestEntityTooLarge, ErrTooLarge)
+	}
+
+	// read one more byte than allowed to detect bodies that are too large
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), middleware.Error(http.StatusRequestEntityTooLarge, ErrTooLarge)
+	}
+
+	return n, err
+}
+
+// lengthReader fails with 400 if the body ends before or continues after the expected length
+type lengthReader struct {
+	reader   io.Reader
+	expected int64
+	received int64
+}
+
+func (l *lengthReader) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.received += int64(n)
+	if l.received > l.expected || ((errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) && l.received != l.expected) {
+		return n, middleware.Error(http.StatusBadRequest, fmt.Errorf("gocart: expected %d bytes but received %d", l.expected, l.received))
+	}
+
+	return n, err
+}
@@ -20,3 +20,17 @@ func setHeader[T any](header http.Header, key string, values Serializer[T]) {
 		header.Add(key, value)
 	}
 }
+
+// setContentType sets the Content-Type to the type of serializer accepted by the request, or its first type
+func setContentType[T any](header http.Header, serializer Serializer[T], request *http.Request) {
+	if serializer == nil {
+		return
+	}
+
+	types := serializer.Type().HttpType
+	if len(types) == 0 {
+		return
+	}
+
+	header.Set("Content-Type", acceptedType(types, request))
+}
@@ -47,3 +47,24 @@ type HeaderWriter interface {
 	// an "Expect: 100-continue" meta.
 	WriteHeader(statusCode int)
 }
+
+// statusWriter remembers the status written by a CartFunc, so the Cart does not write a body after e.g. a redirect
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (writer *statusWriter) WriteHeader(statusCode int) {
+	writer.status = statusCode
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *statusWriter) allowsBody() bool {
+	switch writer.status {
+	case http.StatusNoContent, http.StatusNotModified,
+		http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return false
+	default:
+		return true
+	}
+}
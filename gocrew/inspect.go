@@ -0,0 +1,139 @@
+package gocrew
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/go-chi/chi/v5"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteEntry describes a registered route, see Inspect
+type RouteEntry struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	// Controller is the name of the controller the route is mounted beneath, if any
+	Controller string `json:"controller,omitempty"`
+	// Middlewares is the number of middlewares wrapping the route, including the ones of the routers above it
+	Middlewares int    `json:"middlewares"`
+	Summary     string `json:"summary,omitempty"`
+	Input       string `json:"input,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Hidden      bool   `json:"hidden"`
+}
+
+// RouteTable are all routes of a router in the order they are walked, see Inspect
+type RouteTable []RouteEntry
+
+// Inspect returns the routes beneath router including their information, e.g. for debugging
+func Inspect(router chi.Routes) (RouteTable, error) {
+	var table RouteTable
+	err := walk(
+		router,
+		func(method string, route string, handler http.Handler, controller goflag.ControllerFlag, middlewares int) error {
+			entry := RouteEntry{Method: method, Pattern: route, Middlewares: middlewares}
+			if controller != nil {
+				entry.Controller = controller.Info().Name
+			}
+
+			if typed, ok := handler.(goflag.EndpointFlag); ok {
+				info := typed.Info()
+				entry.Summary = info.Summary
+				entry.Input = typeName(info.Input)
+				entry.Output = typeName(info.Output)
+				entry.Hidden = info.Hidden
+			}
+
+			table = append(table, entry)
+			return nil
+		},
+		func(goflag.ControllerFlag) error { return nil },
+		"",
+		nil,
+		0,
+	)
+
+	return table, err
+}
+
+func typeName(typ *goflag.Type) string {
+	if typ == nil || typ.GoType == nil {
+		return ""
+	}
+
+	name := typ.GoType.String()
+	if len(typ.HttpType) > 0 {
+		name += " (" + strings.Join(typ.HttpType, ", ") + ")"
+	}
+
+	return name
+}
+
+// String renders the table as aligned text
+func (table RouteTable) String() string {
+	builder := &strings.Builder{}
+	writer := tabwriter.NewWriter(builder, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "METHOD\tPATTERN\tCONTROLLER\tMIDDLEWARES\tSUMMARY\tINPUT\tOUTPUT\tHIDDEN")
+	for _, entry := range table {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%t\n",
+			entry.Method, entry.Pattern, entry.Controller, entry.Middlewares, entry.Summary, entry.Input, entry.Output, entry.Hidden)
+	}
+
+	_ = writer.Flush()
+	return builder.String()
+}
+
+var routeTableTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Routes</title></head>
+<body>
+<table>
+<thead><tr><th>Method</th><th>Pattern</th><th>Controller</th><th>Middlewares</th><th>Summary</th><th>Input</th><th>Output</th><th>Hidden</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td>{{.Method}}</td><td>{{.Pattern}}</td><td>{{.Controller}}</td><td>{{.Middlewares}}</td><td>{{.Summary}}</td><td>{{.Input}}</td><td>{{.Output}}</td><td>{{.Hidden}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// InspectHandler serves the RouteTable of router as HTML, or as JSON if requested by the Accept header or ?format=json.
+// The table is inspected on every request, the endpoint is hidden from the documentation:
+//
+//	router.Method(http.MethodGet, "/debug/routes", gocrew.InspectHandler(router))
+func InspectHandler(router chi.Routes) goflag.EndpointFlag {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		table, err := Inspect(router)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "json") {
+			data, err := json.Marshal(table)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = routeTableTemplate.Execute(w, table)
+	})
+
+	info := goflag.EndpointInformation{Hidden: true}
+	info.WithSummary("Route table")
+	return goflag.FlagEndpoint(handler, info)
+}
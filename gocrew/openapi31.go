@@ -4,12 +4,13 @@ import (
 	"encoding/json"
 	"github.com/benni-tec/gocart/goflag"
 	"github.com/go-chi/chi/v5"
+	"github.com/swaggest/jsonschema-go"
 	"github.com/swaggest/openapi-go"
 	"github.com/swaggest/openapi-go/openapi31"
 	swg "github.com/swaggest/swgui"
 	swgui "github.com/swaggest/swgui/v5emb"
 	"net/http"
-	"reflect"
+	"slices"
 )
 
 // +++ Spec +++
@@ -46,6 +47,7 @@ type openapi31Generator struct {
 func (gen *openapi31Generator) Generate(router chi.Routes) (*OpenApi31Spec, error) {
 	reflector := openapi31.NewReflector()
 	reflector.Spec = &openapi31.Spec{Openapi: "3.1.0"}
+	reflector.DefaultOptions = append(reflector.DefaultOptions, jsonschema.InterceptDefName(genericDefName))
 
 	if r, ok := router.(goflag.InformationFlag); ok {
 		reflector.Spec.Info.
@@ -56,10 +58,15 @@ func (gen *openapi31Generator) Generate(router chi.Routes) (*OpenApi31Spec, erro
 	reflector.Spec.Info.WithTitle(gen.title)
 
 	hasDefaultTag := false
+	ids, err := newOperationIDs(router)
+	if err != nil {
+		return nil, err
+	}
 
-	err := Walk(
+	err = walk(
 		router,
-		func(method string, route string, handler http.Handler, controller goflag.ControllerFlag) error {
+		func(method string, route string, handler http.Handler, controller goflag.ControllerFlag, _ int) error {
+			route = openapiPath(route)
 			ctx, err := reflector.NewOperationContext(method, route)
 			if err != nil {
 				return err
@@ -83,26 +90,32 @@ func (gen *openapi31Generator) Generate(router chi.Routes) (*OpenApi31Spec, erro
 				tag = gen.defaultTag.Name
 			}
 
+			ctx.SetID(ids.of(method, route, info.Name))
 			ctx.SetSummary(info.Summary)
 			ctx.SetDescription(info.Description)
-			ctx.SetTags(tag)
+			ctx.SetTags(append([]string{tag}, slices.DeleteFunc(slices.Clone(info.Tags), func(t string) bool { return t == tag })...)...)
+			ctx.SetIsDeprecated(info.IsDeprecated())
+
+			for _, security := range info.Security {
+				ctx.AddSecurity(security.Name, security.Scopes...)
+			}
 
 			// schemas
 			// TODO: set proper contentType
 			if info.Input != nil {
-				dummy := reflect.New(info.Input.GoType).Interface()
+				dummy := dummyOf(info.Input.GoType)
 
 				if len(info.Input.HttpType) == 0 {
 					ctx.AddReqStructure(dummy, openapi.WithHTTPStatus(http.StatusNoContent))
 				}
 
 				for _, typ := range info.Input.HttpType {
-					ctx.AddReqStructure(dummy, openapi.WithContentType(typ))
+					ctx.AddReqStructure(dummy, openapi.WithContentType(typ), withFormat(info.Input.GoType))
 				}
 			}
 
 			if info.Output != nil {
-				dummy := reflect.New(info.Output.GoType).Interface()
+				dummy := dummyOf(info.Output.GoType)
 
 				if len(info.Output.HttpType) == 0 {
 					ctx.AddRespStructure(dummy, openapi.WithHTTPStatus(http.StatusNoContent))
@@ -113,25 +126,53 @@ func (gen *openapi31Generator) Generate(router chi.Routes) (*OpenApi31Spec, erro
 				}
 			}
 
+			for _, response := range info.Responses {
+				if response.Output == nil {
+					ctx.AddRespStructure(nil, openapi.WithHTTPStatus(response.Status), withDescription(response.Description), withHeaders(response.Headers))
+					continue
+				}
+
+				dummy := dummyOf(response.Output.GoType)
+				if len(response.Output.HttpType) == 0 {
+					ctx.AddRespStructure(dummy, openapi.WithHTTPStatus(response.Status), withDescription(response.Description), withHeaders(response.Headers))
+				}
+
+				for _, typ := range response.Output.HttpType {
+					ctx.AddRespStructure(dummy, openapi.WithHTTPStatus(response.Status), openapi.WithContentType(typ), withDescription(response.Description), withHeaders(response.Headers))
+				}
+			}
+
+			if exposer, ok := ctx.(openapi31.OperationExposer); ok && info.CORS != nil {
+				exposer.Operation().WithMapOfAnythingItem("x-cors", corsExtension(info.CORS))
+			}
+
 			return reflector.AddOperation(ctx)
 		},
 		func(controller goflag.ControllerFlag) error {
 			info := controller.Info()
+			for _, existing := range reflector.Spec.Tags {
+				if existing.Name == info.Name {
+					return nil
+				}
+			}
 
 			tag := openapi31.Tag{}
 			tag.WithName(info.Name)
 			tag.WithDescription(info.Description)
 
-			_ = append(reflector.Spec.Tags, tag)
+			reflector.Spec.Tags = append(reflector.Spec.Tags, tag)
 			return nil
 		},
+		"",
+		nil,
+		0,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	if hasDefaultTag {
-		_ = prepend(reflector.Spec.Tags, gen.defaultTag)
+		reflector.Spec.Tags = prepend(reflector.Spec.Tags, gen.defaultTag)
 	}
 
 	spec := OpenApi31Spec(*reflector.Spec)
@@ -0,0 +1,128 @@
+package gocrew
+
+import (
+	"github.com/swaggest/jsonschema-go"
+	"github.com/swaggest/openapi-go"
+	"github.com/swaggest/openapi-go/openapi31"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var bytesType = reflect.TypeOf([]byte{})
+
+// binaryBody is used in place of []byte bodies, so they are documented as binary strings instead of base64.
+type binaryBody []byte
+
+func (binaryBody) JSONSchema() (jsonschema.Schema, error) {
+	schema := jsonschema.Schema{}
+	schema.AddType(jsonschema.String)
+	schema.WithFormat("binary")
+	return schema, nil
+}
+
+func (binaryBody) InlineJSONSchema() {}
+
+// isBinary checks if typ is a (pointer to a) []byte
+func isBinary(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ == bytesType
+}
+
+// withFormat documents non-json request bodies of type []byte as binary
+func withFormat(typ reflect.Type) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		if isBinary(typ) {
+			cu.Format = "binary"
+		}
+	}
+}
+
+// withDescription sets the description of a response, if empty the status text is used
+func withDescription(description string) openapi.ContentOption {
+	return func(cu *openapi.ContentUnit) {
+		if description != "" {
+			cu.Description = description
+		}
+	}
+}
+
+// withHeaders documents additional response headers
+func withHeaders(headers map[string]string) openapi.ContentOption {
+	return openapi.WithCustomize(func(cor openapi.ContentOrReference) {
+		response, ok := cor.(*openapi31.ResponseOrReference)
+		if !ok || response.Response == nil {
+			return
+		}
+
+		for name, description := range headers {
+			header := openapi31.Header{}
+			header.WithDescription(description).WithSchema(map[string]any{"type": "string"})
+			response.Response.WithHeadersItem(name, openapi31.HeaderOrReference{Header: &header})
+		}
+	})
+}
+
+// dummyOf returns a value of typ which can be passed to the reflector
+func dummyOf(typ reflect.Type) any {
+	if isBinary(typ) {
+		return new(binaryBody)
+	}
+
+	return reflect.New(typ).Interface()
+}
+
+// genericDefName names instances of generic types after their type arguments, e.g. Page[User] becomes PageOfUser
+func genericDefName(t reflect.Type, defaultDefName string) string {
+	if !strings.Contains(t.Name(), "[") {
+		return defaultDefName
+	}
+
+	return typeArgName(t.Name())
+}
+
+// typeArgName converts the name of a (generic) type to a schema name, e.g. Map[string,[]pkg.User] becomes MapOfStringAndUserList
+func typeArgName(name string) string {
+	if elem, ok := strings.CutPrefix(name, "[]"); ok {
+		return typeArgName(elem) + "List"
+	}
+
+	if elem, ok := strings.CutPrefix(name, "*"); ok {
+		return typeArgName(elem)
+	}
+
+	base, args, generic := strings.Cut(name, "[")
+	if !generic {
+		// strip the package path
+		base = base[strings.LastIndexAny(base, "/.")+1:]
+
+		runes := []rune(base)
+		runes[0] = unicode.ToUpper(runes[0])
+		return string(runes)
+	}
+
+	base = typeArgName(base)
+	args = strings.TrimSuffix(args, "]")
+
+	var names []string
+	depth, start := 0, 0
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				names = append(names, typeArgName(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	names = append(names, typeArgName(args[start:]))
+	return base + "Of" + strings.Join(names, "And")
+}
@@ -1,5 +1,15 @@
 package gocrew
 
+import (
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 func prepend[T any](array []T, value T) []T {
 	array = append(array, *new(T))
 	copy(array[1:], array)
@@ -11,3 +21,85 @@ func prepend[T any](array []T, value T) []T {
 func P[T any](v T) *T {
 	return &v
 }
+
+// operationIDs hands out unique operationIds, which are either the name of the endpoint or derived from its method and route
+type operationIDs struct {
+	names map[string]bool
+	used  map[string]bool
+}
+
+// newOperationIDs reserves the names of all endpoints, so derived operationIds do not collide with them
+func newOperationIDs(router chi.Routes) (*operationIDs, error) {
+	ids := &operationIDs{names: map[string]bool{}, used: map[string]bool{}}
+	err := Walk(router, func(_ string, _ string, handler http.Handler, _ goflag.ControllerFlag) error {
+		if typed, ok := handler.(goflag.EndpointFlag); ok && typed.Info().Name != "" {
+			ids.names[typed.Info().Name] = true
+		}
+
+		return nil
+	}, func(goflag.ControllerFlag) error { return nil })
+
+	return ids, err
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+func (ids *operationIDs) of(method string, route string, name string) string {
+	id := name
+	if id == "" {
+		id = strings.ToLower(method)
+		for _, segment := range strings.Split(route, "/") {
+			// drop the regex of a parameter, e.g. {id:[0-9]+}
+			segment, _, _ = strings.Cut(segment, ":")
+			for _, word := range nonAlphanumeric.Split(segment, -1) {
+				if word != "" {
+					runes := []rune(word)
+					id += string(unicode.ToUpper(runes[0])) + string(runes[1:])
+				}
+			}
+		}
+	}
+
+	unique := id
+	for i := 2; ids.used[unique] || (name == "" && ids.names[unique]); i++ {
+		unique = id + strconv.Itoa(i)
+	}
+
+	ids.used[unique] = true
+	return unique
+}
+
+var servemuxWildcard = regexp.MustCompile(`\{(\w+)\.\.\.}`)
+
+// openapiPath translates the wildcards of http.ServeMux patterns to path parameters,
+// i.e. {path...} is documented as {path} and the end anchor {$} is dropped
+func openapiPath(route string) string {
+	route = strings.ReplaceAll(route, "{$}", "")
+	return servemuxWildcard.ReplaceAllString(route, "{$1}")
+}
+
+// corsExtension documents the cross-origin policy of an operation as the vendor extension x-cors
+func corsExtension(policy *goflag.CORS) map[string]any {
+	extension := map[string]any{
+		"origins":     policy.Origins,
+		"credentials": policy.Credentials,
+	}
+
+	if len(policy.Methods) > 0 {
+		extension["methods"] = policy.Methods
+	}
+
+	if len(policy.Headers) > 0 {
+		extension["headers"] = policy.Headers
+	}
+
+	if len(policy.ExposedHeaders) > 0 {
+		extension["exposedHeaders"] = policy.ExposedHeaders
+	}
+
+	if policy.MaxAge > 0 {
+		extension["maxAge"] = int(policy.MaxAge.Seconds())
+	}
+
+	return extension
+}
@@ -3,7 +3,9 @@ package gocrew
 import (
 	"github.com/benni-tec/gocart/goflag"
 	"github.com/go-chi/chi/v5"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -11,11 +13,19 @@ type WalkFunc func(method string, route string, handler http.Handler, controller
 type ControllerFunc func(controller goflag.ControllerFlag) error
 
 func Walk(r chi.Routes, walkFn WalkFunc, onController ControllerFunc) error {
-	return walk(r, walkFn, onController, "", nil)
+	return walk(r, func(method string, route string, handler http.Handler, controller goflag.ControllerFlag, _ int) error {
+		return walkFn(method, route, handler, controller)
+	}, onController, "", nil, 0)
 }
 
-// copied from chi, removed middlewares, added controllers
-func walk(r chi.Routes, walkFn WalkFunc, onController ControllerFunc, parentRoute string, controller goflag.ControllerFlag) error {
+// walkMiddlewaresFunc is a WalkFunc that also receives the number of middlewares the handler is wrapped in
+type walkMiddlewaresFunc func(method string, route string, handler http.Handler, controller goflag.ControllerFlag, middlewares int) error
+
+// copied from chi, counting the middlewares and added controllers.
+// The defaults of the routers are not applied, since the information of a gotrac.Route already inherits them.
+func walk(r chi.Routes, walkFn walkMiddlewaresFunc, onController ControllerFunc, parentRoute string, controller goflag.ControllerFlag, middlewares int) error {
+	middlewares += len(r.Middlewares())
+
 	for _, route := range r.Routes() {
 		if route.SubRoutes != nil {
 			current := controller
@@ -28,14 +38,16 @@ func walk(r chi.Routes, walkFn WalkFunc, onController ControllerFunc, parentRout
 				current = cont
 			}
 
-			if err := walk(route.SubRoutes, walkFn, onController, parentRoute+route.Pattern, current); err != nil {
+			if err := walk(route.SubRoutes, walkFn, onController, parentRoute+route.Pattern, current, middlewares); err != nil {
 				return err
 			}
 
 			continue
 		}
 
-		for method, handler := range route.Handlers {
+		// sort the methods to walk in a stable order
+		for _, method := range slices.Sorted(maps.Keys(route.Handlers)) {
+			handler := route.Handlers[method]
 			if method == "*" {
 				// Ignore a "catchAll" method, since we pass down all the specific methods for each route.
 				continue
@@ -45,11 +57,11 @@ func walk(r chi.Routes, walkFn WalkFunc, onController ControllerFunc, parentRout
 			fullRoute = strings.Replace(fullRoute, "/*/", "/", -1)
 
 			if chain, ok := handler.(*chi.ChainHandler); ok {
-				if err := walkFn(method, fullRoute, chain.Endpoint, controller); err != nil {
+				if err := walkFn(method, fullRoute, chain.Endpoint, controller, middlewares+len(chain.Middlewares)); err != nil {
 					return err
 				}
 			} else {
-				if err := walkFn(method, fullRoute, handler, controller); err != nil {
+				if err := walkFn(method, fullRoute, handler, controller, middlewares); err != nil {
 					return err
 				}
 			}
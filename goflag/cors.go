@@ -0,0 +1,87 @@
+package goflag
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// CORS is a cross-origin resource sharing policy of an endpoint, it is enforced by gotrac.CORSMiddleware
+type CORS struct {
+	// Origins are allowed to make cross-origin requests, "*" allows any origin
+	Origins []string
+	// Methods are allowed in preflight requests, if empty the methods routed for the path are allowed whose policy allows the request
+	Methods []string
+	// Headers are the request headers allowed in preflight requests, "*" allows any header
+	Headers []string
+	// ExposedHeaders are the response headers readable by the client
+	ExposedHeaders []string
+	// Credentials allows cookies and authorization headers to be sent
+	Credentials bool
+	// MaxAge is how long the result of a preflight request can be cached, zero omits it
+	MaxAge time.Duration
+}
+
+// NewCORS creates a CORS policy allowing the origins
+func NewCORS(origins ...string) *CORS {
+	return &CORS{Origins: origins}
+}
+
+// Clone returns a copy of the policy that does not share its lists
+func (c *CORS) Clone() *CORS {
+	if c == nil {
+		return nil
+	}
+
+	result := *c
+	result.Origins = slices.Clone(c.Origins)
+	result.Methods = slices.Clone(c.Methods)
+	result.Headers = slices.Clone(c.Headers)
+	result.ExposedHeaders = slices.Clone(c.ExposedHeaders)
+	return &result
+}
+
+func (c *CORS) WithMethods(methods ...string) *CORS {
+	c.Methods = append(c.Methods, methods...)
+	return c
+}
+
+func (c *CORS) WithHeaders(headers ...string) *CORS {
+	c.Headers = append(c.Headers, headers...)
+	return c
+}
+
+func (c *CORS) WithExposedHeaders(headers ...string) *CORS {
+	c.ExposedHeaders = append(c.ExposedHeaders, headers...)
+	return c
+}
+
+func (c *CORS) WithCredentials(credentials bool) *CORS {
+	c.Credentials = credentials
+	return c
+}
+
+func (c *CORS) WithMaxAge(maxAge time.Duration) *CORS {
+	c.MaxAge = maxAge
+	return c
+}
+
+// AllowsOrigin reports whether requests from origin are allowed
+func (c *CORS) AllowsOrigin(origin string) bool {
+	return slices.Contains(c.Origins, "*") || slices.Contains(c.Origins, origin)
+}
+
+// AllowsHeaders reports whether all headers are allowed, the comparison is case-insensitive
+func (c *CORS) AllowsHeaders(headers ...string) bool {
+	if slices.Contains(c.Headers, "*") {
+		return true
+	}
+
+	for _, header := range headers {
+		if !slices.ContainsFunc(c.Headers, func(allowed string) bool { return strings.EqualFold(allowed, header) }) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,121 @@
+package goflag
+
+import "slices"
+
+// DefaultsFlag can be implemented by a router to provide EndpointDefaults to all endpoints beneath it
+type DefaultsFlag interface {
+	Defaults() *EndpointDefaults
+}
+
+// Security is a security requirement of an endpoint, i.e. the name of a security scheme and the required scopes
+type Security struct {
+	Name   string
+	Scopes []string
+}
+
+// EndpointDefaults is information that is inherited by all endpoints beneath a router.
+// Endpoints (and sub-routers) can override it, see Apply.
+type EndpointDefaults struct {
+	Tags      []string
+	Security  []Security
+	Responses []Response
+	// Deprecated is inherited from the parent router if nil
+	Deprecated *bool
+	CORS       *CORS
+}
+
+func (d *EndpointDefaults) WithTags(tags ...string) *EndpointDefaults {
+	d.Tags = append(d.Tags, tags...)
+	return d
+}
+
+// WithSecurity adds a security requirement, any of the requirements has to be met
+func (d *EndpointDefaults) WithSecurity(name string, scopes ...string) *EndpointDefaults {
+	d.Security = append(d.Security, Security{Name: name, Scopes: scopes})
+	return d
+}
+
+// WithResponse adds a common Response, e.g. an error, replacing an existing one with the same status code
+func (d *EndpointDefaults) WithResponse(status int, description string, typ *Type) *EndpointDefaults {
+	d.Responses = withResponse(d.Responses, Response{Status: status, Description: description, Output: typ})
+	return d
+}
+
+func (d *EndpointDefaults) WithDeprecated(deprecated bool) *EndpointDefaults {
+	d.Deprecated = &deprecated
+	return d
+}
+
+// WithCORS sets the cross-origin policy of all endpoints that do not set their own
+func (d *EndpointDefaults) WithCORS(policy *CORS) *EndpointDefaults {
+	d.CORS = policy
+	return d
+}
+
+// Apply returns a copy of info that inherits the defaults:
+//   - the tags are added in front of the ones of info,
+//   - the security requirements are used if info has none (set an empty, non-nil slice to remove them),
+//   - the responses are added unless info has one with the same status code,
+//   - the deprecation is used if info does not set its own,
+//   - and the cross-origin policy is used if info has none.
+func (d *EndpointDefaults) Apply(info *EndpointInformation) *EndpointInformation {
+	result := *info
+
+	result.Tags = nil
+	for _, tag := range slices.Concat(d.Tags, info.Tags) {
+		if !slices.Contains(result.Tags, tag) {
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+
+	if result.Security == nil {
+		result.Security = d.Security
+	}
+
+	result.Responses = slices.Clone(info.Responses)
+	for _, response := range d.Responses {
+		if !slices.ContainsFunc(result.Responses, func(r Response) bool { return r.Status == response.Status }) {
+			result.Responses = append(result.Responses, response)
+		}
+	}
+
+	if result.Deprecated == nil {
+		result.Deprecated = d.Deprecated
+	}
+
+	if result.CORS == nil {
+		result.CORS = d.CORS
+	}
+
+	return &result
+}
+
+// Inherit returns the defaults of a sub-router with these defaults, that inherits the ones of parent
+func (d *EndpointDefaults) Inherit(parent *EndpointDefaults) *EndpointDefaults {
+	info := parent.Apply(&EndpointInformation{
+		Tags:       d.Tags,
+		Security:   d.Security,
+		Responses:  d.Responses,
+		Deprecated: d.Deprecated,
+		CORS:       d.CORS,
+	})
+
+	return &EndpointDefaults{
+		Tags:       info.Tags,
+		Security:   info.Security,
+		Responses:  info.Responses,
+		Deprecated: info.Deprecated,
+		CORS:       info.CORS,
+	}
+}
+
+func withResponse(responses []Response, response Response) []Response {
+	for i, existing := range responses {
+		if existing.Status == response.Status {
+			responses[i] = response
+			return responses
+		}
+	}
+
+	return append(responses, response)
+}
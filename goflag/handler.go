@@ -1,8 +1,10 @@
 package goflag
 
 import (
+	"maps"
 	"net/http"
 	"reflect"
+	"slices"
 )
 
 // EndpointFlag is just a http.Handler which can also provide EndpointInformation
@@ -17,41 +19,168 @@ type Type struct {
 	HttpType []string
 }
 
+// Clone returns a copy of the type that does not share its content types
+func (t *Type) Clone() *Type {
+	if t == nil {
+		return nil
+	}
+
+	return &Type{GoType: t.GoType, HttpType: slices.Clone(t.HttpType)}
+}
+
 // EndpointInformation contains the information that can be set for a handler.
 // This is only readable since handler can be anything provided to gotrac.
 // Once the handler is registered with a Router a Route is returned where the information can be edited.
 type EndpointInformation struct {
 	Information
+	// Name identifies the endpoint, it is used to generate URLs and as the operationId and thus has to be unique
+	Name   string
 	Input  *Type
 	Output *Type
 	Hidden bool
+
+	// Tags are added to the name of the controller the endpoint is mounted beneath
+	Tags []string
+	// Security are the security requirements of which any has to be met
+	Security []Security
+	// Deprecated is inherited from the router if nil, so a route can also un-deprecate itself, see IsDeprecated
+	Deprecated *bool
+	// CORS is the cross-origin policy of the endpoint, if nil cross-origin requests are not answered
+	CORS *CORS
+
+	// Responses are additional responses with a specific status code, e.g. 202 or 303.
+	// Output is documented as the 200 (or 204 if it has no body) response.
+	Responses []Response
+
+	// assigned are the names of the fields set using the With methods, see Assigned
+	assigned []string
+}
+
+// Response is a response of an endpoint with a specific status code
+type Response struct {
+	Status      int
+	Description string
+	Output      *Type
+
+	// Headers are documented in addition to the ones declared by the Output, mapping their name to a description
+	Headers map[string]string
 }
 
 func (c *EndpointInformation) WithSummary(summary string) *EndpointInformation {
+	c.assign("Summary")
 	c.Information.WithSummary(summary)
 	return c
 }
 
 func (c *EndpointInformation) WithDescription(description string) *EndpointInformation {
+	c.assign("Description")
 	c.Information.WithDescription(description)
 	return c
 }
 
+func (c *EndpointInformation) WithName(name string) *EndpointInformation {
+	c.assign("Name")
+	c.Name = name
+	return c
+}
+
 func (c *EndpointInformation) WithInput(typ *Type) *EndpointInformation {
+	c.assign("Input")
 	c.Input = typ
 	return c
 }
 
 func (c *EndpointInformation) WithOutput(typ *Type) *EndpointInformation {
+	c.assign("Output")
 	c.Output = typ
 	return c
 }
 
 func (c *EndpointInformation) WithHidden(hidden bool) *EndpointInformation {
+	c.assign("Hidden")
 	c.Hidden = hidden
 	return c
 }
 
+// WithResponse adds a Response, replacing an existing one with the same status code
+func (c *EndpointInformation) WithResponse(status int, description string, typ *Type) *EndpointInformation {
+	c.assign("Responses")
+	c.Responses = withResponse(c.Responses, Response{Status: status, Description: description, Output: typ})
+	return c
+}
+
+func (c *EndpointInformation) WithTags(tags ...string) *EndpointInformation {
+	c.assign("Tags")
+	c.Tags = append(c.Tags, tags...)
+	return c
+}
+
+// WithSecurity adds a security requirement, any of the requirements has to be met
+func (c *EndpointInformation) WithSecurity(name string, scopes ...string) *EndpointInformation {
+	c.assign("Security")
+	c.Security = append(c.Security, Security{Name: name, Scopes: scopes})
+	return c
+}
+
+func (c *EndpointInformation) WithDeprecated(deprecated bool) *EndpointInformation {
+	c.assign("Deprecated")
+	c.Deprecated = &deprecated
+	return c
+}
+
+// IsDeprecated reports whether the endpoint is deprecated
+func (c *EndpointInformation) IsDeprecated() bool {
+	return c.Deprecated != nil && *c.Deprecated
+}
+
+// Assigned returns the names of the fields that have been set using the With methods, even if the value did not change.
+// A gotrac.Route uses them to know which fields it overrides.
+func (c *EndpointInformation) Assigned() []string {
+	return c.assigned
+}
+
+func (c *EndpointInformation) assign(field string) {
+	if !slices.Contains(c.assigned, field) {
+		c.assigned = append(c.assigned, field)
+	}
+}
+
+// Clone returns a deep copy of the information, so it can be edited without changing the original.
+// The copy starts without any Assigned fields.
+func (c *EndpointInformation) Clone() *EndpointInformation {
+	result := *c
+	result.assigned = nil
+	result.Input = c.Input.Clone()
+	result.Output = c.Output.Clone()
+	result.Tags = slices.Clone(c.Tags)
+	result.CORS = c.CORS.Clone()
+
+	if c.Deprecated != nil {
+		deprecated := *c.Deprecated
+		result.Deprecated = &deprecated
+	}
+
+	result.Security = slices.Clone(c.Security)
+	for i, security := range result.Security {
+		result.Security[i].Scopes = slices.Clone(security.Scopes)
+	}
+
+	result.Responses = slices.Clone(c.Responses)
+	for i, response := range result.Responses {
+		result.Responses[i].Output = response.Output.Clone()
+		result.Responses[i].Headers = maps.Clone(response.Headers)
+	}
+
+	return &result
+}
+
+// WithCORS sets the cross-origin policy, overriding the one of the router
+func (c *EndpointInformation) WithCORS(policy *CORS) *EndpointInformation {
+	c.assign("CORS")
+	c.CORS = policy
+	return c
+}
+
 type flaggedEndpoint struct {
 	http.Handler
 	flag[EndpointInformation]
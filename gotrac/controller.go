@@ -4,6 +4,7 @@ import (
 	"github.com/benni-tec/gocart/goflag"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"reflect"
 )
 
 func WithName(name string, handler goflag.Controller) goflag.ControllerFlag {
@@ -43,3 +44,40 @@ func (c *controllerImpl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (c *controllerImpl) Info() *goflag.ControllerInformation {
 	return &c.info
 }
+
+func (c *controllerImpl) mountContainer(parent *container) {
+	if sub, ok := c.handler.(containerMounter); ok {
+		sub.mountContainer(parent)
+	}
+}
+
+func (c *controllerImpl) routesVersion() *routeVersion {
+	if sub, ok := c.handler.(versioned); ok {
+		return sub.routesVersion()
+	}
+
+	return &routeVersion{}
+}
+
+func (c *controllerImpl) missingDependencies() []reflect.Type {
+	if sub, ok := c.handler.(dependencyVerifier); ok {
+		return sub.missingDependencies()
+	}
+
+	return nil
+}
+
+func (c *controllerImpl) mountDefaults(parent *defaults) {
+	if sub, ok := c.handler.(defaultsMounter); ok {
+		sub.mountDefaults(parent)
+	}
+}
+
+// Defaults returns the defaults of the controller's router, or nil if it does not provide any
+func (c *controllerImpl) Defaults() *goflag.EndpointDefaults {
+	if sub, ok := c.handler.(goflag.DefaultsFlag); ok {
+		return sub.Defaults()
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package gotrac
+
+import (
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CORSMiddleware enforces the goflag.CORS policy of the route serving a request,
+// it is set using goflag.EndpointInformation.WithCORS or for all routes of a router using goflag.EndpointDefaults.WithCORS.
+//
+// Preflight requests are answered using the policy of the route that is registered for the requested method,
+// if there is none or the request is not allowed by its policy no CORS headers are set.
+// Preflight requests for paths that are not routed or have an explicit OPTIONS route are passed on instead.
+// It has to be used on the root Router.
+func CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		requested := r.Header.Get("Access-Control-Request-Method")
+		if r.Method == http.MethodOptions && requested != "" && isPreflight(r) {
+			preflight(w, r, origin, requested)
+			return
+		}
+
+		if route := CurrentRoute(r.Context()); route != nil && route.Info() != nil {
+			if policy := route.Info().CORS; policy != nil && policy.AllowsOrigin(origin) {
+				allowOrigin(w.Header(), policy, origin)
+				if len(policy.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isPreflight reports whether an OPTIONS request is answered by the middleware,
+// i.e. the path is routed and there is no OPTIONS route that takes precedence
+func isPreflight(r *http.Request) bool {
+	root, ok := r.Context().Value(routerKey{}).(rootRouter)
+	if !ok {
+		return true
+	}
+
+	path := routePath(r)
+	return len(AllowedMethods(root, path)) > 0 && root.Find(chi.NewRouteContext(), http.MethodOptions, path) == ""
+}
+
+// preflight answers a preflight request for the requested method
+func preflight(w http.ResponseWriter, r *http.Request, origin string, requested string) {
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+	defer w.WriteHeader(http.StatusNoContent)
+
+	root, ok := r.Context().Value(routerKey{}).(rootRouter)
+	if !ok {
+		return
+	}
+
+	policy := policyOf(root, requested, r)
+	if policy == nil {
+		return
+	}
+
+	var headers []string
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+
+	// the answer may be cached for all methods, so only the ones whose policy allows the request are advertised
+	methods := policy.Methods
+	if len(methods) == 0 {
+		for _, method := range AllowedMethods(root, routePath(r)) {
+			if other := policyOf(root, method, r); other != nil && other.AllowsOrigin(origin) && other.AllowsHeaders(headers...) {
+				methods = append(methods, method)
+			}
+		}
+	}
+
+	if !policy.AllowsOrigin(origin) || !containsFold(methods, requested) || !policy.AllowsHeaders(headers...) {
+		return
+	}
+
+	allowOrigin(w.Header(), policy, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(headers) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+
+	if policy.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+}
+
+// policyOf returns the policy of the route serving method for the path of r, HEAD falls back to the GET route
+func policyOf(root rootRouter, method string, r *http.Request) *goflag.CORS {
+	route := root.matchRoute(method, r)
+	if route == nil && method == http.MethodHead {
+		route = root.matchRoute(http.MethodGet, r)
+	}
+
+	if route == nil || route.Info() == nil {
+		return nil
+	}
+
+	return route.Info().CORS
+}
+
+func allowOrigin(header http.Header, policy *goflag.CORS, origin string) {
+	// the wildcard cannot be used with credentials
+	if slices.Contains(policy.Origins, "*") && !policy.Credentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if policy.Credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,166 @@
+package gotrac
+
+import (
+	"context"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MatchedRoute describes the route that is serving a request, see CurrentRoute
+type MatchedRoute struct {
+	Method string
+	// Pattern is the full pattern of the route, including the prefixes of the sub-routers it is mounted on
+	Pattern string
+	// Controller is the innermost controller the route is mounted beneath, or nil
+	Controller goflag.ControllerFlag
+	// Handler is the registered handler, i.e. the Route, or nil if the route is a stub of a mounted router
+	Handler http.Handler
+
+	info func() *goflag.EndpointInformation
+}
+
+// Info returns the information of the endpoint, or nil if the handler does not provide any.
+// It is only computed once the first middleware asks for it.
+func (route *MatchedRoute) Info() *goflag.EndpointInformation {
+	if route.info == nil {
+		return nil
+	}
+
+	return route.info()
+}
+
+// CurrentRoute returns the route that is serving the request of ctx, or nil if no route matched.
+// It is set by the root Router before any middleware runs, so middlewares can be driven by the information of the route.
+// Therefore, the root Router looks up the route before the request is routed.
+func CurrentRoute(ctx context.Context) *MatchedRoute {
+	route, _ := ctx.Value(currentRouteKey{}).(*MatchedRoute)
+	return route
+}
+
+type currentRouteKey struct{}
+
+// routeVersion counts the registrations beneath a router tree, so its route index is rebuilt once it changes.
+// The trees a router is mounted on count its registrations as well.
+type routeVersion struct {
+	value   atomic.Uint64
+	parents []*routeVersion
+}
+
+func (version *routeVersion) changed() {
+	version.value.Add(1)
+	for _, parent := range version.parents {
+		parent.changed()
+	}
+}
+
+// mount links the version to the one of the tree it is mounted on
+func (version *routeVersion) mount(parent *routeVersion) {
+	if parent != version {
+		version.parents = append(version.parents, parent)
+	}
+
+	parent.changed()
+}
+
+// versioned is implemented by routers that count their registrations
+type versioned interface {
+	routesVersion() *routeVersion
+}
+
+// mountVersion links the version of h (if it has one) to version, which is marked as changed in any case
+func mountVersion(version *routeVersion, h http.Handler) {
+	if sub, ok := h.(versioned); ok {
+		sub.routesVersion().mount(version)
+		return
+	}
+
+	version.changed()
+}
+
+// routeIndex maps "METHOD pattern" to the handlers of a router tree,
+// it is built when the root router serves a request and rebuilt once routes have been registered since
+type routeIndex struct {
+	mu      sync.RWMutex
+	version uint64
+	routes  map[string]indexedRoute
+}
+
+type indexedRoute struct {
+	handler    http.Handler
+	controller goflag.ControllerFlag
+}
+
+// match finds the route of the request within router, method is the one the request is routed by
+func (index *routeIndex) match(router chi.Routes, version *routeVersion, method string, request *http.Request) *MatchedRoute {
+	routes := index.lookup(router, version.value.Load())
+
+	pattern := router.Find(chi.NewRouteContext(), method, routePath(request))
+	if pattern == "" {
+		return nil
+	}
+
+	matched := &MatchedRoute{Method: method, Pattern: pattern}
+
+	route, ok := routes[method+" "+pattern]
+	if !ok {
+		// routes registered using Handle match all methods
+		if route, ok = routes["* "+pattern]; !ok {
+			return matched
+		}
+	}
+
+	matched.Controller = route.controller
+	matched.Handler = route.handler
+	if flag, ok := route.handler.(goflag.EndpointFlag); ok {
+		matched.info = sync.OnceValue(flag.Info)
+	}
+
+	return matched
+}
+
+// lookup returns the routes of router, building them if they are outdated
+func (index *routeIndex) lookup(router chi.Routes, version uint64) map[string]indexedRoute {
+	index.mu.RLock()
+	routes := index.routes
+	current := routes != nil && index.version == version
+	index.mu.RUnlock()
+
+	if current {
+		return routes
+	}
+
+	routes = map[string]indexedRoute{}
+	indexRoutes(routes, router, "", nil)
+
+	index.mu.Lock()
+	index.routes, index.version = routes, version
+	index.mu.Unlock()
+
+	return routes
+}
+
+func indexRoutes(routes map[string]indexedRoute, router chi.Routes, parent string, controller goflag.ControllerFlag) {
+	for _, route := range router.Routes() {
+		if route.SubRoutes != nil {
+			current := controller
+			if flag, ok := route.SubRoutes.(goflag.ControllerFlag); ok {
+				current = flag
+			}
+
+			indexRoutes(routes, route.SubRoutes, parent+strings.TrimSuffix(route.Pattern, "/*"), current)
+			continue
+		}
+
+		for method, handler := range route.Handlers {
+			if chain, ok := handler.(*chi.ChainHandler); ok {
+				handler = chain.Endpoint
+			}
+
+			routes[method+" "+parent+route.Pattern] = indexedRoute{handler: handler, controller: controller}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package gotrac
+
+import (
+	"errors"
+	middleware2 "github.com/benni-tec/gocart/middleware"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"time"
+)
+
+// DefaultStack are the middlewares installed by Default, they are installed in the order of the fields
+type DefaultStack struct {
+	RedirectSlashes bool
+	// RealIP sets the remote address from the X-Real-IP or X-Forwarded-For headers, only enable it behind a trusted proxy
+	RealIP    bool
+	RequestID bool
+	// Logger is the logging middleware, e.g. middleware.Logger, nil disables logging
+	Logger    func(http.Handler) http.Handler
+	Recoverer bool
+	// Timeout cancels the context of a request after the duration, zero disables it
+	Timeout time.Duration
+	// BodyLimit is the maximum size of a request body in bytes, zero disables it
+	BodyLimit int64
+	// Errors installs the middleware.ErrorMiddleware, which requires RequestID
+	Errors bool
+	// Middlewares are installed after all others
+	Middlewares []func(http.Handler) http.Handler
+}
+
+// DefaultOption configures the DefaultStack, see Default
+type DefaultOption func(stack *DefaultStack)
+
+// Default creates a new Router and adds common middlewares, that should be present on the root router.
+// Without options these are RedirectSlashes, RequestID, Logger, Recoverer and the ErrorMiddleware,
+// which can be changed using options, e.g. Production or Logger.
+// It panics if the stack is invalid, see NewDefault.
+func Default(options ...DefaultOption) Router {
+	router, err := NewDefault(options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return router
+}
+
+// NewDefault is like Default, but returns an error if the stack is invalid, e.g. the ErrorMiddleware is missing RequestID
+func NewDefault(options ...DefaultOption) (Router, error) {
+	stack := DefaultStack{
+		RedirectSlashes: true,
+		RequestID:       true,
+		Logger:          middleware.Logger,
+		Recoverer:       true,
+		Errors:          true,
+	}
+
+	for _, option := range options {
+		option(&stack)
+	}
+
+	if err := stack.validate(); err != nil {
+		return nil, err
+	}
+
+	mux := NewRouter()
+	mux.Use(stack.middlewares()...)
+	return mux, nil
+}
+
+func (stack *DefaultStack) validate() error {
+	if stack.Errors && !stack.RequestID {
+		return errors.New("gotrac: the ErrorMiddleware requires the RequestID middleware")
+	}
+
+	if stack.Timeout < 0 || stack.BodyLimit < 0 {
+		return errors.New("gotrac: the timeout and body limit must not be negative")
+	}
+
+	return nil
+}
+
+func (stack *DefaultStack) middlewares() []func(http.Handler) http.Handler {
+	var middlewares []func(http.Handler) http.Handler
+	if stack.RedirectSlashes {
+		middlewares = append(middlewares, middleware.RedirectSlashes)
+	}
+
+	if stack.RealIP {
+		middlewares = append(middlewares, middleware.RealIP)
+	}
+
+	if stack.RequestID {
+		middlewares = append(middlewares, middleware.RequestID)
+	}
+
+	if stack.Logger != nil {
+		middlewares = append(middlewares, stack.Logger)
+	}
+
+	if stack.Recoverer {
+		middlewares = append(middlewares, middleware.Recoverer)
+	}
+
+	if stack.Timeout > 0 {
+		middlewares = append(middlewares, middleware.Timeout(stack.Timeout))
+	}
+
+	if stack.BodyLimit > 0 {
+		middlewares = append(middlewares, middleware.RequestSize(stack.BodyLimit))
+	}
+
+	if stack.Errors {
+		middlewares = append(middlewares, middleware2.ErrorMiddleware)
+	}
+
+	return append(middlewares, stack.Middlewares...)
+}
+
+// +++ Options +++
+
+// Production is the preset for production: it additionally cancels requests after 60 seconds and limits the request bodies to 10 MiB.
+// RealIP is not enabled, add RealIP(true) if the router is only reachable through a trusted proxy.
+func Production() DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.RedirectSlashes = true
+		stack.RealIP = false
+		stack.RequestID = true
+		stack.Logger = middleware.Logger
+		stack.Recoverer = true
+		stack.Timeout = 60 * time.Second
+		stack.BodyLimit = 10 << 20
+		stack.Errors = true
+	}
+}
+
+// Development is the preset for development, without the timeout and body limit
+func Development() DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.RedirectSlashes = true
+		stack.RealIP = false
+		stack.RequestID = true
+		stack.Logger = middleware.Logger
+		stack.Recoverer = true
+		stack.Timeout = 0
+		stack.BodyLimit = 0
+		stack.Errors = true
+	}
+}
+
+func RedirectSlashes(enabled bool) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.RedirectSlashes = enabled
+	}
+}
+
+func RealIP(enabled bool) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.RealIP = enabled
+	}
+}
+
+func RequestID(enabled bool) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.RequestID = enabled
+	}
+}
+
+// Logger replaces the logging middleware, nil disables logging
+func Logger(logger func(http.Handler) http.Handler) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.Logger = logger
+	}
+}
+
+func Recoverer(enabled bool) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.Recoverer = enabled
+	}
+}
+
+// Timeout cancels the context of a request after timeout, zero disables it
+func Timeout(timeout time.Duration) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.Timeout = timeout
+	}
+}
+
+// BodyLimit limits the size of request bodies to bytes, zero disables it
+func BodyLimit(bytes int64) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.BodyLimit = bytes
+	}
+}
+
+// Errors enables the middleware.ErrorMiddleware, which requires RequestID
+func Errors(enabled bool) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.Errors = enabled
+	}
+}
+
+// Middlewares adds middlewares after the ones of the stack
+func Middlewares(middlewares ...func(http.Handler) http.Handler) DefaultOption {
+	return func(stack *DefaultStack) {
+		stack.Middlewares = append(stack.Middlewares, middlewares...)
+	}
+}
@@ -0,0 +1,24 @@
+package gotrac
+
+import "github.com/benni-tec/gocart/goflag"
+
+// defaults of a Router, linked to the ones of the router it is mounted on (or the one a group was created from)
+type defaults struct {
+	own    goflag.EndpointDefaults
+	parent *defaults
+}
+
+// effective returns the own defaults inheriting the ones of all parents
+func (d *defaults) effective() *goflag.EndpointDefaults {
+	if d.parent == nil {
+		own := d.own
+		return &own
+	}
+
+	return d.own.Inherit(d.parent.effective())
+}
+
+// defaultsMounter is implemented by routers whose defaults can be linked to the ones of the router they are mounted on
+type defaultsMounter interface {
+	mountDefaults(parent *defaults)
+}
@@ -0,0 +1,146 @@
+package gotrac
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+)
+
+// PreflightFunc adds headers to the automatic response of an OPTIONS request, e.g. the CORS preflight headers.
+// allowed are the methods that are routed for the requested path, see AllowedMethods.
+type PreflightFunc func(header http.Header, request *http.Request, allowed []string)
+
+// methods are the standard HTTP methods in the order they are listed in an Allow header
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// AllowedMethods returns the methods that are routed for path within router.
+// HEAD is allowed for every GET route and OPTIONS for every routed path, since both are answered automatically.
+func AllowedMethods(router chi.Routes, path string) []string {
+	routed := map[string]bool{}
+	found := false
+	for _, method := range methods {
+		routed[method] = router.Find(chi.NewRouteContext(), method, path) != ""
+		found = found || routed[method]
+	}
+
+	if !found {
+		return nil
+	}
+
+	routed[http.MethodHead] = routed[http.MethodHead] || routed[http.MethodGet]
+	routed[http.MethodOptions] = true
+
+	var allowed []string
+	for _, method := range methods {
+		if routed[method] {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
+}
+
+// routePath returns the path chi routes the request by
+func routePath(request *http.Request) string {
+	path := request.URL.RawPath
+	if path == "" {
+		path = request.URL.Path
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	return path
+}
+
+// methodHandlers are shared by a router and its groups, like chi does for its inline routers
+type methodHandlers struct {
+	notAllowed http.HandlerFunc
+	preflight  PreflightFunc
+	// notFound is only used by the ServeMux, the Mux uses the one of chi
+	notFound http.HandlerFunc
+}
+
+// routeMethod returns the method a request is routed by, i.e. GET for a HEAD request without an explicit HEAD route
+func routeMethod(router chi.Routes, request *http.Request) string {
+	if request.Method != http.MethodHead {
+		return request.Method
+	}
+
+	path := routePath(request)
+	if router.Find(chi.NewRouteContext(), http.MethodHead, path) == "" && router.Find(chi.NewRouteContext(), http.MethodGet, path) != "" {
+		return http.MethodGet
+	}
+
+	return request.Method
+}
+
+// methodNotAllowed is installed as the MethodNotAllowed handler of chi, see notAllowed
+func (m *Mux) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	notAllowed(m, w, r)
+}
+
+// notAllowed answers OPTIONS requests and responds with 405 otherwise (or calls the MethodNotAllowed handler),
+// the Allow header is computed from the root router serving the request.
+func notAllowed(router rootRouter, w http.ResponseWriter, r *http.Request) {
+	root, ok := r.Context().Value(routerKey{}).(rootRouter)
+	if !ok {
+		root = router
+	}
+
+	allowed := AllowedMethods(root, routePath(r))
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	if r.Method == http.MethodOptions {
+		if preflight := root.methodHandlers().preflight; preflight != nil {
+			preflight(w.Header(), r, allowed)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// the handler of a sub-router takes precedence, otherwise the one of the root router is used
+	handler := router.methodHandlers().notAllowed
+	if handler == nil {
+		handler = root.methodHandlers().notAllowed
+	}
+
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// headWriter discards the body of a GET handler serving a HEAD request
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *headWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap allows the http.ResponseController to access the underlying writer
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
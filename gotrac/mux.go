@@ -1,47 +1,88 @@
 package gotrac
 
 import (
+	"context"
 	"github.com/benni-tec/gocart/goflag"
-	middleware2 "github.com/benni-tec/gocart/middleware"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"net/http"
+	"reflect"
+	"strings"
 )
 
 // Mux implements the Router using a chi.Router
 type Mux struct {
-	router chi.Router
-	info   goflag.Information
+	router    chi.Router
+	info      goflag.Information
+	container *container
+	defaults  *defaults
+	index     *routeIndex
+	handlers  *methodHandlers
+	tree      *muxTree
 }
 
-// Default creates a new Router and adds common middlewares, that should be present on the root router
-func Default() Router {
-	mux := NewRouter()
-	mux.Use(middleware.RedirectSlashes)
-	mux.Use(middleware.Logger)
-	mux.Use(middleware.RequestID)
-	mux.Use(middleware.Recoverer)
-	mux.Use(middleware2.ErrorMiddleware)
-
-	return mux
+// muxTree is the state shared by a Mux and its groups, like the tree of chi
+type muxTree struct {
+	// mounts maps the stub patterns chi registers for a mount to the mounted router, see Find
+	mounts map[string]chi.Routes
+	// registered are the routes in the order they were registered, since chi silently replaces duplicates
+	registered []registration
+	version    routeVersion
 }
 
 // NewRouter creates a new Router without any middlewares.
 func NewRouter() Router {
-	mux := wrapToRouter(chi.NewRouter())
+	return newMux()
+}
+
+func newMux() *Mux {
+	mux := wrapToRouter(chi.NewRouter(), newContainer(), &defaults{}, &methodHandlers{}, &muxTree{mounts: map[string]chi.Routes{}})
+	mux.router.MethodNotAllowed(mux.methodNotAllowed)
 	return mux
 }
 
-func wrapToRouter(r chi.Router) Router {
+func wrapToRouter(r chi.Router, container *container, defaults *defaults, handlers *methodHandlers, tree *muxTree) *Mux {
 	return &Mux{
-		router: r,
+		router:    r,
+		container: container,
+		defaults:  defaults,
+		index:     &routeIndex{},
+		handlers:  handlers,
+		tree:      tree,
 	}
 }
 
 func (m *Mux) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	// the root router creates the scope for per-request services
+	if requestInstances(request.Context()) == nil {
+		serveRoot(m, m.container, writer, request, func(w http.ResponseWriter, r *http.Request, method string) {
+			// HEAD requests are served by the GET route unless a HEAD route is registered explicitly
+			if method != r.Method {
+				rctx := chi.NewRouteContext()
+				rctx.Routes = m
+				rctx.RouteMethod = method
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+			}
+
+			m.router.ServeHTTP(w, r)
+		})
+		return
+	}
+
 	m.router.ServeHTTP(writer, request)
 }
 
+func (m *Mux) matchRoute(method string, request *http.Request) *MatchedRoute {
+	return m.index.match(m, &m.tree.version, method, request)
+}
+
+func (m *Mux) routesVersion() *routeVersion {
+	return &m.tree.version
+}
+
+func (m *Mux) methodHandlers() *methodHandlers {
+	return m.handlers
+}
+
 func (m *Mux) Routes() []chi.Route {
 	return m.router.Routes()
 }
@@ -51,11 +92,34 @@ func (m *Mux) Middlewares() chi.Middlewares {
 }
 
 func (m *Mux) Match(rctx *chi.Context, method, path string) bool {
-	return m.router.Match(rctx, method, path)
+	return m.Find(rctx, method, path) != ""
 }
 
 func (m *Mux) Find(rctx *chi.Context, method, path string) string {
-	return m.router.Find(rctx, method, path)
+	// chi reports the pattern of the parent router if a sub-router does not route the method,
+	// since it is kept in rctx. Therefore, a fresh context is used and only the parameters are copied.
+	found := chi.NewRouteContext()
+	pattern := m.router.Find(found, method, path)
+	for i, key := range found.URLParams.Keys {
+		rctx.URLParams.Add(key, found.URLParams.Values[i])
+	}
+
+	// chi reports the stubs of a mount (without a trailing slash) for every method,
+	// while they are routed to the root of the mounted router
+	if sub, ok := m.tree.mounts[pattern]; ok {
+		subPattern := sub.Find(rctx, method, "/")
+		if subPattern == "" {
+			return ""
+		}
+
+		return strings.TrimSuffix(pattern, "/") + subPattern
+	}
+
+	return pattern
+}
+
+func (m *Mux) registrations() []registration {
+	return m.tree.registered
 }
 
 func (m *Mux) Use(middlewares ...func(http.Handler) http.Handler) {
@@ -63,7 +127,7 @@ func (m *Mux) Use(middlewares ...func(http.Handler) http.Handler) {
 }
 
 func (m *Mux) With(middlewares ...func(http.Handler) http.Handler) Router {
-	return wrapToRouter(m.router.With(middlewares...))
+	return wrapToRouter(m.router.With(middlewares...), m.container, &defaults{parent: m.defaults}, m.handlers, m.tree)
 }
 
 func (m *Mux) Group(fn func(r Router)) Router {
@@ -77,7 +141,8 @@ func (m *Mux) Group(fn func(r Router)) Router {
 }
 
 func (m *Mux) Route(pattern string, fn func(r Router)) Router {
-	sub := NewRouter()
+	sub := newMux()
+	sub.container.mount(m.container)
 
 	if fn != nil {
 		fn(sub)
@@ -88,30 +153,100 @@ func (m *Mux) Route(pattern string, fn func(r Router)) Router {
 }
 
 func (m *Mux) Mount(pattern string, h http.Handler) {
+	if sub, ok := h.(containerMounter); ok {
+		sub.mountContainer(m.container)
+	}
+
+	if sub, ok := h.(defaultsMounter); ok {
+		sub.mountDefaults(m.defaults)
+	}
+
+	if sub, ok := h.(chi.Routes); ok && !strings.HasSuffix(pattern, "/") {
+		m.tree.mounts[pattern] = sub
+		m.tree.mounts[pattern+"/"] = sub
+	}
+
 	m.router.Mount(pattern, h)
+	mountVersion(&m.tree.version, h)
 }
 
 func (m *Mux) Handle(pattern string, h http.Handler) Route {
-	actor := wrapToHandler(h)
+	actor := register(wrapToHandler(h), h, m.container, m.defaults)
 	m.router.Handle(pattern, actor)
+	m.tree.registered = append(m.tree.registered, registration{method: "*", pattern: pattern})
+	m.tree.version.changed()
 	return actor
 }
 
 func (m *Mux) HandleFunc(pattern string, h http.HandlerFunc) Route {
-	actor := wrapFuncToHandler(h)
+	actor := register(wrapFuncToHandler(h), h, m.container, m.defaults)
 	m.router.Handle(pattern, actor)
+	m.tree.registered = append(m.tree.registered, registration{method: "*", pattern: pattern})
+	m.tree.version.changed()
 	return actor
 }
 
 func (m *Mux) Method(method, pattern string, h http.Handler) Route {
-	actor := wrapToHandler(h)
+	actor := register(wrapToHandler(h), h, m.container, m.defaults)
 	m.router.Method(method, pattern, actor)
+	m.tree.registered = append(m.tree.registered, registration{method: strings.ToUpper(method), pattern: pattern})
+	m.tree.version.changed()
 	return actor
 }
 
 func (m *Mux) MethodFunc(method, pattern string, h http.HandlerFunc) Route {
-	actor := wrapFuncToHandler(h)
+	actor := register(wrapFuncToHandler(h), h, m.container, m.defaults)
 	m.router.Method(method, pattern, actor)
+	m.tree.registered = append(m.tree.registered, registration{method: strings.ToUpper(method), pattern: pattern})
+	m.tree.version.changed()
+	return actor
+}
+
+// +++ Methods +++
+func (m *Mux) Connect(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodConnect, pattern, h)
+}
+
+func (m *Mux) Delete(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodDelete, pattern, h)
+}
+
+func (m *Mux) Get(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodGet, pattern, h)
+}
+
+func (m *Mux) Head(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodHead, pattern, h)
+}
+
+func (m *Mux) Options(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodOptions, pattern, h)
+}
+
+func (m *Mux) Patch(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodPatch, pattern, h)
+}
+
+func (m *Mux) Post(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodPost, pattern, h)
+}
+
+func (m *Mux) Put(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodPut, pattern, h)
+}
+
+func (m *Mux) Trace(pattern string, h http.HandlerFunc) Route {
+	return m.MethodFunc(http.MethodTrace, pattern, h)
+}
+
+// register links the route to the services and defaults of the router and checks its dependencies
+func register(actor *routeImpl, h http.Handler, container *container, defaults *defaults) *routeImpl {
+	if dependent, ok := h.(Dependent); ok {
+		container.require(dependent.Dependencies())
+	}
+
+	actor.container = container
+	actor.defaults = defaults
 	return actor
 }
 
@@ -119,8 +254,53 @@ func (m *Mux) NotFound(h http.HandlerFunc) {
 	m.router.NotFound(h)
 }
 
+// MethodNotAllowed defines the handler responding whenever a method is not allowed,
+// the Allow header is set before it is called and OPTIONS requests are still answered automatically.
+// It is also used for the routes of sub-routers that do not define their own.
 func (m *Mux) MethodNotAllowed(h http.HandlerFunc) {
-	m.router.MethodNotAllowed(h)
+	m.handlers.notAllowed = h
+}
+
+func (m *Mux) WithPreflight(fn PreflightFunc) Router {
+	m.handlers.preflight = fn
+	return m
+}
+
+// +++ Services +++
+
+func (m *Mux) Provide(typ reflect.Type, scope Scope, factory Factory) {
+	m.container.provide(typ, scope, factory)
+}
+
+// containerMounter is implemented by routers whose services can be linked to the ones of the router they are mounted on
+type containerMounter interface {
+	mountContainer(parent *container)
+}
+
+func (m *Mux) mountContainer(parent *container) {
+	m.container.mount(parent)
+}
+
+func (m *Mux) missingDependencies() []reflect.Type {
+	return m.container.missing()
+}
+
+// +++ Defaults +++
+
+func (m *Mux) Defaults() *goflag.EndpointDefaults {
+	return &m.defaults.own
+}
+
+func (m *Mux) WithDefaults(fn func(defaults *goflag.EndpointDefaults)) Router {
+	if fn != nil {
+		fn(&m.defaults.own)
+	}
+
+	return m
+}
+
+func (m *Mux) mountDefaults(parent *defaults) {
+	m.defaults.parent = parent
 }
 
 // +++ Information +++
@@ -0,0 +1,224 @@
+package gotrac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrUnknownRoute is returned by URL if no route with the name is registered
+	ErrUnknownRoute = errors.New("gotrac: unknown route")
+	// ErrMissingParam is returned by URL if a path parameter of the route was not given
+	ErrMissingParam = errors.New("gotrac: missing path parameter")
+	// ErrInvalidParam is returned by URL if a path parameter does not match the regular expression of the pattern, e.g. {id:[0-9]+}
+	ErrInvalidParam = errors.New("gotrac: invalid path parameter")
+)
+
+// URL generates the path of the route called name (see Route.WithName) within the router serving the request of ctx,
+// see Reverse for the params.
+func URL(ctx context.Context, name string, params any) (string, error) {
+	router, ok := ctx.Value(routerKey{}).(chi.Routes)
+	if !ok {
+		return "", errors.New("gotrac: no router is serving this request")
+	}
+
+	return Reverse(router, name, params)
+}
+
+// Reverse generates the path of the route called name (see Route.WithName) within router,
+// including the prefixes of the sub-routers it is mounted on.
+//
+// The params can be a struct, whose fields tagged with path and query (like the input of a gocart.Cart) are used,
+// or a map[string]any, whose keys are first used as path parameters while the remaining ones are added to the query.
+// Zero values are omitted from the query (but not from the path), the remainder of a wildcard pattern can be given as the path parameter "*".
+// Path parameters have to match the regular expression of the pattern, if it has one.
+func Reverse(router chi.Routes, name string, params any) (string, error) {
+	pattern, err := findRoute(router, name, "")
+	if err != nil {
+		return "", err
+	}
+
+	if pattern == "" {
+		return "", fmt.Errorf("%w %q", ErrUnknownRoute, name)
+	}
+
+	path, query, err := paramsOf(params)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	var invalid error
+	expanded := patternParam.ReplaceAllStringFunc(pattern, func(param string) string {
+		key, expression, _ := strings.Cut(strings.Trim(param, "{}"), ":")
+		value, ok := path[key]
+		if !ok {
+			missing = append(missing, key)
+			return param
+		}
+
+		if expression != "" && invalid == nil {
+			if matched, err := regexp.MatchString("^(?:"+expression+")$", value); err != nil || !matched {
+				invalid = fmt.Errorf("%w %s=%q for route %q, it has to match %s", ErrInvalidParam, key, value, name, expression)
+			}
+		}
+
+		delete(query, key)
+		return url.PathEscape(value)
+	})
+
+	if strings.HasSuffix(expanded, "/*") {
+		expanded = strings.TrimSuffix(expanded, "*") + strings.TrimPrefix(path["*"], "/")
+		delete(query, "*")
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w %s for route %q", ErrMissingParam, strings.Join(missing, ", "), name)
+	}
+
+	if invalid != nil {
+		return "", invalid
+	}
+
+	if len(query) > 0 {
+		values := url.Values{}
+		for key, value := range query {
+			values.Set(key, value)
+		}
+
+		expanded += "?" + values.Encode()
+	}
+
+	return expanded, nil
+}
+
+// patternParam matches the parameters of a chi pattern, e.g. {id} or {id:[0-9]+}
+var patternParam = regexp.MustCompile(`\{[^{}]*(\{[^{}]*}[^{}]*)*}`)
+
+// findRoute returns the full pattern of the route called name
+func findRoute(router chi.Routes, name string, parent string) (string, error) {
+	found := ""
+	for _, route := range router.Routes() {
+		pattern := strings.Replace(parent+route.Pattern, "/*/", "/", -1)
+		if route.SubRoutes != nil {
+			sub, err := findRoute(route.SubRoutes, name, strings.TrimSuffix(pattern, "/*"))
+			if err != nil {
+				return "", err
+			}
+
+			if sub != "" {
+				if found != "" && found != sub {
+					return "", fmt.Errorf("gotrac: the route name %q is used by %s and %s", name, found, sub)
+				}
+
+				found = sub
+			}
+
+			continue
+		}
+
+		for _, handler := range route.Handlers {
+			if chain, ok := handler.(*chi.ChainHandler); ok {
+				handler = chain.Endpoint
+			}
+
+			named, ok := handler.(Route)
+			if !ok || named.Info().Name != name {
+				continue
+			}
+
+			if found != "" && found != pattern {
+				return "", fmt.Errorf("gotrac: the route name %q is used by %s and %s", name, found, pattern)
+			}
+
+			found = pattern
+		}
+	}
+
+	return found, nil
+}
+
+// paramsOf returns the path and query parameters of params
+func paramsOf(params any) (map[string]string, map[string]string, error) {
+	path := map[string]string{}
+	query := map[string]string{}
+	if params == nil {
+		return path, query, nil
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(params))
+	switch val.Kind() {
+	case reflect.Map:
+		// every entry can be a path parameter, the ones that are not end up in the query
+		for _, key := range val.MapKeys() {
+			value := val.MapIndex(key)
+			if value.Kind() == reflect.Interface {
+				value = value.Elem()
+			}
+
+			if !value.IsValid() {
+				continue
+			}
+
+			name := fmt.Sprint(key.Interface())
+			path[name] = formatParam(value)
+			if !value.IsZero() {
+				query[name] = formatParam(value)
+			}
+		}
+
+		return path, query, nil
+	case reflect.Struct:
+		collectParams(val, path, query)
+		return path, query, nil
+	default:
+		return nil, nil, fmt.Errorf("gotrac: the params have to be a struct or a map, not %s", val.Type())
+	}
+}
+
+func collectParams(val reflect.Value, path map[string]string, query map[string]string) {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		value := val.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectParams(value, path, query)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("path"); ok {
+			path[name] = formatParam(value)
+		} else if name, ok := field.Tag.Lookup("query"); ok && !value.IsZero() {
+			query[name] = formatParam(value)
+		}
+	}
+}
+
+// formatParam formats value like the gocart decoders expect it, i.e. slices are separated by ","
+func formatParam(value reflect.Value) string {
+	value = reflect.Indirect(value)
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		var values []string
+		for i := range value.Len() {
+			values = append(values, fmt.Sprint(value.Index(i).Interface()))
+		}
+
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// routerKey holds the root router serving a request
+type routerKey struct{}
@@ -0,0 +1,200 @@
+package gotrac
+
+import (
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// PathsFlag can be implemented by a controller struct to set the pattern of its handler methods,
+// the map is indexed by the method name and the value is either a pattern or a method and a pattern,
+// e.g. {"GetByID": "/{id}", "Search": "POST /search"}.
+type PathsFlag interface {
+	Paths() map[string]string
+}
+
+// DescribeFlag can be implemented by a controller struct to describe its handler methods,
+// it is called with the name of each registered method.
+type DescribeFlag interface {
+	Describe(method string, info *goflag.EndpointInformation)
+}
+
+var verbs = []string{
+	http.MethodConnect,
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodTrace,
+}
+
+var handlerFuncType = reflect.TypeOf(http.HandlerFunc(nil))
+var handlerType = reflect.TypeOf((*http.Handler)(nil)).Elem()
+
+// Register creates a sub-router from the exported handler methods of the controller struct,
+// which can then be mounted, e.g. router.Mount("/users", gotrac.MustRegister(&UserController{})).
+//
+// Handler methods either have the signature func(http.ResponseWriter, *http.Request)
+// or take no arguments and return a http.Handler (e.g. a gocart.Cart), which is called once when registering.
+//
+// The HTTP method and pattern are derived from the method name, e.g. GetUsers becomes GET /users and Post becomes POST /.
+// They can be overridden by implementing PathsFlag, the information by implementing DescribeFlag.
+//
+// The name of the controller is the type name without a "Controller" suffix,
+// it can be changed by adding a blank field with tags, e.g. `_ struct{} name:"Users" description:"Manages users"`.
+func Register(controller any) (goflag.ControllerFlag, error) {
+	val := reflect.ValueOf(controller)
+	typ := val.Type()
+
+	paths := map[string]string{}
+	if flag, ok := controller.(PathsFlag); ok {
+		paths = flag.Paths()
+	}
+
+	router := NewRouter()
+	for i := range typ.NumMethod() {
+		method := typ.Method(i)
+
+		verb, pattern, ok := routeOf(method.Name, paths)
+		if !ok {
+			continue
+		}
+
+		handler, err := handlerOf(val.Method(i))
+		if err != nil {
+			return nil, fmt.Errorf("gotrac: %s.%s %w", typ, method.Name, err)
+		}
+
+		route := router.Method(verb, pattern, handler)
+		if flag, ok := controller.(DescribeFlag); ok {
+			route.WithInfo(func(info *goflag.EndpointInformation) {
+				flag.Describe(method.Name, info)
+			})
+		}
+	}
+
+	return &controllerImpl{
+		handler: router,
+		info:    controllerInfoOf(typ),
+	}, nil
+}
+
+// MustRegister is like Register but panics if the controller is invalid.
+func MustRegister(controller any) goflag.ControllerFlag {
+	registered, err := Register(controller)
+	if err != nil {
+		panic(err)
+	}
+
+	return registered
+}
+
+// routeOf returns the HTTP method and pattern of a handler method, ok is false if it is not a handler
+func routeOf(name string, paths map[string]string) (verb string, pattern string, ok bool) {
+	for _, candidate := range verbs {
+		prefix := string(candidate[0]) + strings.ToLower(candidate[1:])
+
+		rest, found := strings.CutPrefix(name, prefix)
+		if found && (rest == "" || unicode.IsUpper(rune(rest[0]))) {
+			verb = candidate
+			pattern = "/" + kebab(rest)
+			ok = true
+			break
+		}
+	}
+
+	if path, found := paths[name]; found {
+		if method, rest, hasMethod := strings.Cut(path, " "); hasMethod {
+			verb = strings.ToUpper(method)
+			path = strings.TrimSpace(rest)
+		}
+
+		pattern = path
+		ok = verb != ""
+	}
+
+	return verb, pattern, ok
+}
+
+// handlerOf converts a handler method to a http.Handler
+func handlerOf(method reflect.Value) (http.Handler, error) {
+	typ := method.Type()
+
+	if typ.ConvertibleTo(handlerFuncType) {
+		return method.Convert(handlerFuncType).Interface().(http.HandlerFunc), nil
+	}
+
+	if typ.NumIn() == 0 && typ.NumOut() == 1 && typ.Out(0).Implements(handlerType) {
+		handler, _ := method.Call(nil)[0].Interface().(http.Handler)
+		if handler == nil {
+			return nil, errors.New("returned a nil handler")
+		}
+
+		return handler, nil
+	}
+
+	return nil, errors.New("is not a handler, expected func(http.ResponseWriter, *http.Request) or func() http.Handler")
+}
+
+func controllerInfoOf(typ reflect.Type) goflag.ControllerInformation {
+	elem := typ
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	info := goflag.ControllerInformation{
+		Name: strings.TrimSuffix(elem.Name(), "Controller"),
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return info
+	}
+
+	for i := range elem.NumField() {
+		field := elem.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("name"); ok {
+			info.Name = name
+		}
+
+		if summary, ok := field.Tag.Lookup("summary"); ok {
+			info.Summary = summary
+		}
+
+		if description, ok := field.Tag.Lookup("description"); ok {
+			info.Description = description
+		}
+	}
+
+	return info
+}
+
+// kebab converts a CamelCase name to kebab-case, e.g. UsersByID becomes users-by-id
+func kebab(name string) string {
+	runes := []rune(name)
+
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previousLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				builder.WriteRune('-')
+			}
+		}
+
+		builder.WriteRune(unicode.ToLower(r))
+	}
+
+	return builder.String()
+}
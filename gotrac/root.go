@@ -0,0 +1,42 @@
+package gotrac
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+// rootRouter is implemented by the routers that create the scope of a request when they serve it as the root router
+type rootRouter interface {
+	chi.Routes
+	// matchRoute finds the route serving method for the path of request
+	matchRoute(method string, request *http.Request) *MatchedRoute
+	methodHandlers() *methodHandlers
+}
+
+// serveRoot creates the scope of a request served by the root router and calls serve with the method the request is routed by:
+// it verifies the dependencies, creates the per-request services and looks up the route serving the request.
+// Requests are answered with 500 if dependencies are missing, call Verify on startup to detect them before.
+func serveRoot(root rootRouter, container *container, writer http.ResponseWriter, request *http.Request, serve func(w http.ResponseWriter, r *http.Request, method string)) {
+	if container.verify(root) != nil {
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	instances := newInstances()
+	defer instances.close()
+
+	ctx := withResolver(request.Context(), &resolver{container: container, request: instances})
+	ctx = context.WithValue(ctx, routerKey{}, root)
+
+	method := routeMethod(root, request)
+	if method != request.Method {
+		writer = &headWriter{ResponseWriter: writer}
+	}
+
+	if route := root.matchRoute(method, request); route != nil {
+		ctx = context.WithValue(ctx, currentRouteKey{}, route)
+	}
+
+	serve(writer, request.WithContext(ctx), method)
+}
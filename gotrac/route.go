@@ -3,6 +3,8 @@ package gotrac
 import (
 	"github.com/benni-tec/gocart/goflag"
 	"net/http"
+	"reflect"
+	"slices"
 )
 
 // Route is a handler that has been registered to a Router.
@@ -10,63 +12,143 @@ import (
 type Route interface {
 	goflag.EndpointFlag
 	WithInfo(fn func(route *goflag.EndpointInformation)) Route
+
+	// WithName names the route, so its URL can be generated using URL or Reverse
+	WithName(name string) Route
 }
 
 type routeImpl struct {
 	handler http.HandlerFunc
-	info    goflag.EndpointInformation
-}
 
-func wrapToHandler(handler http.Handler) *routeImpl {
-	var info *goflag.EndpointInformation = nil
-	if with, ok := handler.(goflag.EndpointFlag); ok {
-		info = with.Info()
-	}
+	// source is the flag of the registered handler, its information is read whenever the route's is,
+	// so changes made to the handler after it was registered are not lost.
+	// The fields changed using WithInfo (see infoFields) are kept in edited and replace the ones of the source.
+	source  goflag.EndpointFlag
+	edited  *goflag.EndpointInformation
+	changed [][]int
 
-	return wrapToActorFunc(info, func(writer http.ResponseWriter, request *http.Request) {
-		handler.ServeHTTP(writer, request)
-	})
+	// container of the Router the route is registered with, instances holds the per-route services
+	container *container
+	instances *instances
+
+	// defaults of the Router the route is registered with
+	defaults *defaults
 }
 
-func wrapToActorFunc(info *goflag.EndpointInformation, handler http.HandlerFunc) *routeImpl {
-	if info == nil {
-		info = &goflag.EndpointInformation{}
+func wrapToHandler(handler http.Handler) *routeImpl {
+	route := wrapFuncToHandler(handler.ServeHTTP)
+	if flag, ok := handler.(goflag.EndpointFlag); ok {
+		route.source = flag
 	}
 
-	return &routeImpl{
-		handler: handler,
-		info:    *info,
-	}
+	return route
 }
 
 func wrapFuncToHandler(handler http.HandlerFunc) *routeImpl {
 	return &routeImpl{
-		handler: handler,
-		info: goflag.EndpointInformation{
-			Information: goflag.Information{
-				Summary:     "",
-				Description: "",
-			},
-			Input:  nil,
-			Output: nil,
-			Hidden: false,
-		},
+		handler:   handler,
+		instances: newInstances(),
 	}
 }
 
 func (a *routeImpl) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if a.container != nil {
+		instances := requestInstances(request.Context())
+		if instances == nil {
+			instances = newInstances()
+			defer instances.close()
+		}
+
+		request = request.WithContext(withResolver(request.Context(), &resolver{
+			container: a.container,
+			request:   instances,
+			route:     a.instances,
+		}))
+	}
+
 	a.handler(writer, request)
 }
 
 func (a *routeImpl) Info() *goflag.EndpointInformation {
-	cast := goflag.EndpointInformation(a.info)
-	return &cast
+	info := a.merged()
+	if a.defaults != nil {
+		return a.defaults.effective().Apply(info)
+	}
+
+	return info
+}
+
+// merged returns a copy of the information of the source with the fields changed using WithInfo
+func (a *routeImpl) merged() *goflag.EndpointInformation {
+	info := goflag.EndpointInformation{}
+	if a.source != nil {
+		info = *a.source.Info()
+	}
+
+	if a.edited != nil {
+		target := reflect.ValueOf(&info).Elem()
+		edited := reflect.ValueOf(a.edited).Elem()
+		for _, index := range a.changed {
+			target.FieldByIndex(index).Set(edited.FieldByIndex(index))
+		}
+	}
+
+	// copy the information, so it does not share anything with the handler or the edits
+	return info.Clone()
 }
 
+// WithInfo applies fn once to a copy of the information, the fields it changed or set using the With methods then override the ones of the handler
 func (a *routeImpl) WithInfo(fn func(route *goflag.EndpointInformation)) Route {
-	if fn != nil {
-		fn(&a.info)
+	if fn == nil {
+		return a
 	}
 
+	before := a.merged()
+	after := before.Clone()
+	fn(after)
+
+	// fields set using the With methods override the ones of the handler, even if they are set to the same value
+	previous, current := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+	for _, index := range infoFields {
+		if slices.ContainsFunc(a.changed, func(changed []int) bool { return slices.Equal(changed, index) }) {
+			continue
+		}
+
+		name := current.Type().FieldByIndex(index).Name
+		if slices.Contains(after.Assigned(), name) || !reflect.DeepEqual(previous.FieldByIndex(index).Interface(), current.FieldByIndex(index).Interface()) {
+			a.changed = append(a.changed, index)
+		}
+	}
+
+	a.edited = after
 	return a
 }
+
+// infoFields are the indices of the exported fields of the EndpointInformation, the ones of embedded structs (i.e. Information) are listed individually
+var infoFields = fieldsOf(reflect.TypeFor[goflag.EndpointInformation](), nil)
+
+func fieldsOf(typ reflect.Type, parent []int) [][]int {
+	var fields [][]int
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		index := append(slices.Clone(parent), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, fieldsOf(field.Type, index)...)
+			continue
+		}
+
+		fields = append(fields, index)
+	}
+
+	return fields
+}
+
+func (a *routeImpl) WithName(name string) Route {
+	return a.WithInfo(func(info *goflag.EndpointInformation) {
+		info.Name = name
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/benni-tec/gocart/goflag"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"reflect"
 )
 
 // Router consisting of the gotrac routing methods used by chi's Mux,
@@ -15,6 +16,11 @@ type Router interface {
 	goflag.InformationFlag
 	WithInfo(fn func(info *goflag.Information)) Router
 
+	// DefaultsFlag provides the defaults that are inherited by all endpoints of this Router,
+	// including the ones of groups and sub-routers, which can override them.
+	goflag.DefaultsFlag
+	WithDefaults(fn func(defaults *goflag.EndpointDefaults)) Router
+
 	// +++ Router +++
 
 	// Use appends one or more middlewares onto the Router stack.
@@ -44,13 +50,31 @@ type Router interface {
 	Method(method, pattern string, h http.Handler) Route
 	MethodFunc(method, pattern string, h http.HandlerFunc) Route
 
-	// TODO: add methods
+	// HTTP-method routing along `pattern`
+	Connect(pattern string, h http.HandlerFunc) Route
+	Delete(pattern string, h http.HandlerFunc) Route
+	Get(pattern string, h http.HandlerFunc) Route
+	Head(pattern string, h http.HandlerFunc) Route
+	Options(pattern string, h http.HandlerFunc) Route
+	Patch(pattern string, h http.HandlerFunc) Route
+	Post(pattern string, h http.HandlerFunc) Route
+	Put(pattern string, h http.HandlerFunc) Route
+	Trace(pattern string, h http.HandlerFunc) Route
+
+	// Provide registers a factory for services of type typ, that can be resolved by all routes of this Router
+	// and its sub-routers using Resolve, see also the generic Provide function.
+	// Providers have to be registered before the root Router serves its first request, registering one afterwards panics.
+	Provide(typ reflect.Type, scope Scope, factory Factory)
 
 	// NotFound defines a handler to respond whenever a route could
 	// not be found.
 	NotFound(h http.HandlerFunc)
 
 	// MethodNotAllowed defines a handler to respond whenever a method is
-	// not allowed.
+	// not allowed. The Allow header is set before the handler is called.
 	MethodNotAllowed(h http.HandlerFunc)
+
+	// WithPreflight adds headers to the automatic responses of OPTIONS requests, e.g. for CORS preflights.
+	// OPTIONS requests are answered automatically for every routed path unless an OPTIONS route is registered.
+	WithPreflight(fn PreflightFunc) Router
 }
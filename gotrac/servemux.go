@@ -0,0 +1,549 @@
+package gotrac
+
+import (
+	"fmt"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ServeMux implements the Router using the http.ServeMux of the standard library,
+// i.e. patterns use its syntax like "/items/{id}" or "/files/{path...}" and the method can be part of the pattern given to Handle.
+//
+// Since the http.ServeMux cannot be walked, the routes are kept in a registry which provides the chi.Routes,
+// so the router can be documented by gocrew like a Mux (wildcards like {path...} are documented as the parameter {path}).
+// The http.ServeMux is built from the registry when a request is served and rebuilt once routes have been registered since.
+// Like with the http.ServeMux, registering a pattern that conflicts with another one panics.
+type ServeMux struct {
+	node      *serveNode
+	inline    chi.Middlewares
+	info      goflag.Information
+	container *container
+	defaults  *defaults
+	handlers  *methodHandlers
+}
+
+// serveNode is the registry of a ServeMux, it is shared with its groups
+type serveNode struct {
+	middlewares chi.Middlewares
+	routes      []serveRoute
+	mounts      []serveMount
+
+	// parents are the nodes this one is mounted on, probe contains the patterns of all routes beneath this node
+	parents []serveParent
+	probe   *serveBuild
+
+	mu      sync.Mutex
+	built   atomic.Pointer[serveBuild]
+	index   *routeIndex
+	version routeVersion
+}
+
+type serveParent struct {
+	node   *serveNode
+	prefix string
+}
+
+type serveRoute struct {
+	method  string
+	pattern string
+	handler http.Handler
+}
+
+type serveMount struct {
+	pattern     string
+	handler     http.Handler
+	middlewares chi.Middlewares
+}
+
+// serveBuild is a http.ServeMux built from the registry of a ServeMux
+type serveBuild struct {
+	version uint64
+	mux     *http.ServeMux
+	handler http.Handler
+	// patterns maps the patterns of mux to the full chi patterns, mounted maps the prefixes of mounted chi.Routes to them
+	patterns map[string]string
+	mounted  map[string]chi.Routes
+}
+
+// NewServeMux creates a new Router backed by a http.ServeMux without any middlewares
+func NewServeMux() Router {
+	return newServeMux()
+}
+
+func newServeMux() *ServeMux {
+	return &ServeMux{
+		node:      &serveNode{probe: newServeBuild(0), index: &routeIndex{}},
+		container: newContainer(),
+		defaults:  &defaults{},
+		handlers:  &methodHandlers{},
+	}
+}
+
+func newServeBuild(version uint64) *serveBuild {
+	return &serveBuild{
+		version:  version,
+		mux:      http.NewServeMux(),
+		patterns: map[string]string{},
+		mounted:  map[string]chi.Routes{},
+	}
+}
+
+func (s *ServeMux) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	build := s.current()
+
+	// the root router creates the scope for per-request services
+	if requestInstances(request.Context()) == nil {
+		serveRoot(s, s.container, writer, request, func(w http.ResponseWriter, r *http.Request, _ string) {
+			build.handler.ServeHTTP(w, r)
+		})
+		return
+	}
+
+	build.handler.ServeHTTP(writer, request)
+}
+
+// current returns the http.ServeMux built from the registry, building it again if routes have been registered since
+func (s *ServeMux) current() *serveBuild {
+	version := s.node.version.value.Load()
+	if build := s.node.built.Load(); build != nil && build.version == version {
+		return build
+	}
+
+	s.node.mu.Lock()
+	defer s.node.mu.Unlock()
+
+	if build := s.node.built.Load(); build != nil && build.version == version {
+		return build
+	}
+
+	build := s.build(version)
+	s.node.built.Store(build)
+	return build
+}
+
+// build registers the routes of the registry, including the ones of mounted ServeMuxes, with a new http.ServeMux
+func (s *ServeMux) build(version uint64) *serveBuild {
+	build := newServeBuild(version)
+	s.node.register(build, "", nil)
+
+	build.handler = s.node.middlewares.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := build.mux.Handler(r); pattern != "" {
+			build.mux.ServeHTTP(w, r)
+			return
+		}
+
+		if len(AllowedMethods(s, routePath(r))) > 0 {
+			notAllowed(s, w, r)
+			return
+		}
+
+		if s.handlers.notFound != nil {
+			s.handlers.notFound(w, r)
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+
+	return build
+}
+
+// register adds the routes beneath node to build
+func (node *serveNode) register(build *serveBuild, prefix string, middlewares chi.Middlewares) {
+	for _, route := range node.routes {
+		build.route(prefix, middlewares, route)
+	}
+
+	for _, mount := range node.mounts {
+		build.mount(prefix, middlewares, mount)
+	}
+}
+
+// route adds a route of a node mounted at prefix
+func (build *serveBuild) route(prefix string, middlewares chi.Middlewares, route serveRoute) {
+	path := prefix + route.pattern
+	if prefix != "" && route.pattern == "/" {
+		// the root of a sub-router matches its prefix, like with chi
+		path = prefix
+	} else if strings.HasSuffix(path, "/") {
+		// patterns ending with a slash only match themselves, like with chi
+		path += "{$}"
+	}
+
+	pattern := path
+	if route.method != "*" {
+		pattern = route.method + " " + path
+	}
+
+	build.handle(pattern, prefix+route.pattern, chi.Chain(middlewares...).Handler(route.handler))
+}
+
+// mount adds a handler mounted on a node mounted at prefix, the routes of a ServeMux are added themselves
+func (build *serveBuild) mount(prefix string, middlewares chi.Middlewares, mount serveMount) {
+	path := prefix + strings.TrimSuffix(mount.pattern, "/")
+	chain := append(middlewares[:len(middlewares):len(middlewares)], mount.middlewares...)
+	if sub, ok := mount.handler.(*ServeMux); ok {
+		sub.node.register(build, path, append(chain, sub.node.middlewares...))
+		return
+	}
+
+	handler := chi.Chain(chain...).Handler(stripSegments(strings.Count(path, "/"), mount.handler))
+	build.handle(path, path+"/*", handler)
+	build.handle(path+"/", path+"/*", handler)
+	if sub, ok := mount.handler.(chi.Routes); ok {
+		build.mounted[path] = sub
+	}
+}
+
+// handle registers handler with the http.ServeMux, which panics if the pattern is invalid or conflicts with another one
+func (build *serveBuild) handle(pattern string, full string, handler http.Handler) {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(fmt.Sprintf("gotrac: %v", r))
+		}
+	}()
+
+	build.mux.Handle(pattern, handler)
+	build.patterns[pattern] = full
+}
+
+// check calls fn with the probe of node and the ones of the nodes it is mounted on, together with the prefix of node within them,
+// so a conflicting pattern panics when it is registered
+func (node *serveNode) check(prefix string, fn func(probe *serveBuild, prefix string)) {
+	fn(node.probe, prefix)
+	for _, parent := range node.parents {
+		parent.node.check(parent.prefix+prefix, fn)
+	}
+}
+
+// stripSegments removes the first n segments of the path before calling handler
+func stripSegments(n int, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := *r.URL
+		u.Path = trimSegments(u.Path, n)
+		if u.RawPath != "" {
+			u.RawPath = trimSegments(u.RawPath, n)
+		}
+
+		stripped := r.Clone(r.Context())
+		stripped.URL = &u
+		handler.ServeHTTP(w, stripped)
+	})
+}
+
+func trimSegments(path string, n int) string {
+	segments := strings.SplitN(path, "/", n+2)
+	if len(segments) < n+2 {
+		return "/"
+	}
+
+	return "/" + segments[n+1]
+}
+
+func (s *ServeMux) matchRoute(method string, request *http.Request) *MatchedRoute {
+	return s.node.index.match(s, &s.node.version, method, request)
+}
+
+func (s *ServeMux) routesVersion() *routeVersion {
+	return &s.node.version
+}
+
+func (s *ServeMux) methodHandlers() *methodHandlers {
+	return s.handlers
+}
+
+func (s *ServeMux) registrations() []registration {
+	registrations := make([]registration, 0, len(s.node.routes))
+	for _, route := range s.node.routes {
+		registrations = append(registrations, registration{method: route.method, pattern: route.pattern})
+	}
+
+	return registrations
+}
+
+// +++ Routes +++
+
+func (s *ServeMux) Routes() []chi.Route {
+	var routes []chi.Route
+	index := map[string]int{}
+	for _, route := range s.node.routes {
+		i, ok := index[route.pattern]
+		if !ok {
+			i = len(routes)
+			index[route.pattern] = i
+			routes = append(routes, chi.Route{Pattern: route.pattern, Handlers: map[string]http.Handler{}})
+		}
+
+		routes[i].Handlers[route.method] = route.handler
+	}
+
+	for _, mount := range s.node.mounts {
+		sub, _ := mount.handler.(chi.Routes)
+		routes = append(routes, chi.Route{
+			Pattern:   strings.TrimSuffix(mount.pattern, "/") + "/*",
+			Handlers:  map[string]http.Handler{"*": mount.handler},
+			SubRoutes: sub,
+		})
+	}
+
+	return routes
+}
+
+func (s *ServeMux) Middlewares() chi.Middlewares {
+	if s.inline != nil {
+		return s.inline
+	}
+
+	return s.node.middlewares
+}
+
+func (s *ServeMux) Match(rctx *chi.Context, method, path string) bool {
+	return s.Find(rctx, method, path) != ""
+}
+
+// Find returns the full chi pattern of the route serving method and path,
+// HEAD requests are only found if a HEAD route is registered explicitly (the http.ServeMux serves them using the GET route).
+func (s *ServeMux) Find(rctx *chi.Context, method, path string) string {
+	build := s.current()
+
+	_, pattern := build.mux.Handler(&http.Request{Method: method, URL: &url.URL{Path: path}})
+	if method == http.MethodHead && strings.HasPrefix(pattern, http.MethodGet+" ") {
+		return ""
+	}
+
+	full, ok := build.patterns[pattern]
+	if !ok || !strings.HasSuffix(full, "/*") {
+		return full
+	}
+
+	// mounted routers are asked for the remainder of the path
+	prefix := strings.TrimSuffix(full, "/*")
+	sub, ok := build.mounted[prefix]
+	if !ok {
+		return full
+	}
+
+	subPattern := sub.Find(rctx, method, trimSegments(path, strings.Count(prefix, "/")))
+	if subPattern == "" {
+		return ""
+	}
+
+	return prefix + subPattern
+}
+
+// +++ Router +++
+
+func (s *ServeMux) Use(middlewares ...func(http.Handler) http.Handler) {
+	if s.inline != nil {
+		s.inline = append(s.inline, middlewares...)
+		return
+	}
+
+	s.node.middlewares = append(s.node.middlewares, middlewares...)
+	s.node.version.changed()
+}
+
+func (s *ServeMux) With(middlewares ...func(http.Handler) http.Handler) Router {
+	inline := append(s.inline[:len(s.inline):len(s.inline)], middlewares...)
+	if inline == nil {
+		inline = chi.Middlewares{}
+	}
+
+	return &ServeMux{
+		node:      s.node,
+		inline:    inline,
+		container: s.container,
+		defaults:  &defaults{parent: s.defaults},
+		handlers:  s.handlers,
+	}
+}
+
+func (s *ServeMux) Group(fn func(r Router)) Router {
+	inline := s.With()
+
+	if fn != nil {
+		fn(inline)
+	}
+
+	return inline
+}
+
+func (s *ServeMux) Route(pattern string, fn func(r Router)) Router {
+	sub := newServeMux()
+	sub.container.mount(s.container)
+
+	if fn != nil {
+		fn(sub)
+	}
+
+	s.Mount(pattern, sub)
+	return sub
+}
+
+func (s *ServeMux) Mount(pattern string, h http.Handler) {
+	if sub, ok := h.(containerMounter); ok {
+		sub.mountContainer(s.container)
+	}
+
+	if sub, ok := h.(defaultsMounter); ok {
+		sub.mountDefaults(s.defaults)
+	}
+
+	mount := serveMount{pattern: pattern, handler: h, middlewares: s.inline}
+	s.node.check("", func(probe *serveBuild, prefix string) {
+		probe.mount(prefix, nil, mount)
+	})
+
+	s.node.mounts = append(s.node.mounts, mount)
+	if sub, ok := h.(*ServeMux); ok {
+		sub.node.parents = append(sub.node.parents, serveParent{node: s.node, prefix: strings.TrimSuffix(pattern, "/")})
+	}
+
+	mountVersion(&s.node.version, h)
+}
+
+// Handle registers h for pattern, which can start with the method like "GET /items/{id}" (otherwise all methods are matched)
+func (s *ServeMux) Handle(pattern string, h http.Handler) Route {
+	method := "*"
+	if before, after, ok := strings.Cut(pattern, " "); ok && !strings.Contains(before, "/") {
+		method, pattern = before, strings.TrimSpace(after)
+	}
+
+	return s.Method(method, pattern, h)
+}
+
+func (s *ServeMux) HandleFunc(pattern string, h http.HandlerFunc) Route {
+	return s.Handle(pattern, h)
+}
+
+func (s *ServeMux) Method(method, pattern string, h http.Handler) Route {
+	actor := register(wrapToHandler(h), h, s.container, s.defaults)
+	s.add(method, pattern, actor)
+	return actor
+}
+
+func (s *ServeMux) MethodFunc(method, pattern string, h http.HandlerFunc) Route {
+	actor := register(wrapFuncToHandler(h), h, s.container, s.defaults)
+	s.add(method, pattern, actor)
+	return actor
+}
+
+func (s *ServeMux) add(method string, pattern string, actor *routeImpl) {
+	var handler http.Handler = actor
+	if len(s.inline) > 0 {
+		handler = chi.Chain(s.inline...).Handler(actor)
+	}
+
+	route := serveRoute{method: strings.ToUpper(method), pattern: pattern, handler: handler}
+	s.node.check("", func(probe *serveBuild, prefix string) {
+		probe.route(prefix, nil, route)
+	})
+
+	s.node.routes = append(s.node.routes, route)
+	s.node.version.changed()
+}
+
+// +++ Methods +++
+func (s *ServeMux) Connect(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodConnect, pattern, h)
+}
+
+func (s *ServeMux) Delete(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodDelete, pattern, h)
+}
+
+func (s *ServeMux) Get(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodGet, pattern, h)
+}
+
+func (s *ServeMux) Head(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodHead, pattern, h)
+}
+
+func (s *ServeMux) Options(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodOptions, pattern, h)
+}
+
+func (s *ServeMux) Patch(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodPatch, pattern, h)
+}
+
+func (s *ServeMux) Post(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodPost, pattern, h)
+}
+
+func (s *ServeMux) Put(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodPut, pattern, h)
+}
+
+func (s *ServeMux) Trace(pattern string, h http.HandlerFunc) Route {
+	return s.MethodFunc(http.MethodTrace, pattern, h)
+}
+
+func (s *ServeMux) NotFound(h http.HandlerFunc) {
+	s.handlers.notFound = h
+}
+
+// MethodNotAllowed defines the handler responding whenever a method is not allowed,
+// the Allow header is set before it is called and OPTIONS requests are still answered automatically.
+// It is also used for the routes of sub-routers that do not define their own.
+func (s *ServeMux) MethodNotAllowed(h http.HandlerFunc) {
+	s.handlers.notAllowed = h
+}
+
+func (s *ServeMux) WithPreflight(fn PreflightFunc) Router {
+	s.handlers.preflight = fn
+	return s
+}
+
+// +++ Services +++
+
+func (s *ServeMux) Provide(typ reflect.Type, scope Scope, factory Factory) {
+	s.container.provide(typ, scope, factory)
+}
+
+func (s *ServeMux) mountContainer(parent *container) {
+	s.container.mount(parent)
+}
+
+func (s *ServeMux) missingDependencies() []reflect.Type {
+	return s.container.missing()
+}
+
+// +++ Defaults +++
+
+func (s *ServeMux) Defaults() *goflag.EndpointDefaults {
+	return &s.defaults.own
+}
+
+func (s *ServeMux) WithDefaults(fn func(defaults *goflag.EndpointDefaults)) Router {
+	if fn != nil {
+		fn(&s.defaults.own)
+	}
+
+	return s
+}
+
+func (s *ServeMux) mountDefaults(parent *defaults) {
+	s.defaults.parent = parent
+}
+
+// +++ Information +++
+
+func (s *ServeMux) Info() *goflag.Information {
+	return &s.info
+}
+
+func (s *ServeMux) WithInfo(fn func(info *goflag.Information)) Router {
+	if fn != nil {
+		fn(&s.info)
+	}
+
+	return s
+}
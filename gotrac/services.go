@@ -0,0 +1,295 @@
+package gotrac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Scope determines how long an instance created by a provider lives.
+type Scope int
+
+const (
+	// Singleton instances are created once per Router that provides them.
+	Singleton Scope = iota
+	// PerRequest instances are created once per request and closed (if they implement io.Closer) at the end of it.
+	PerRequest
+	// PerRoute instances are created once per registered Route.
+	PerRoute
+)
+
+// Factory creates an instance of a provided service, the context is the one of the request that first required it.
+type Factory func(ctx context.Context) (any, error)
+
+// Dependent can be implemented by a handler to declare the services it requires.
+// They are checked once, when the root Router serves its first request (or earlier by calling Verify),
+// if any of them is not provided the error is logged and every request is answered with 500.
+type Dependent interface {
+	Dependencies() []reflect.Type
+}
+
+// Provide registers a factory for T with the router using the given Scope, see Router.Provide.
+func Provide[T any](router Router, scope Scope, factory func(ctx context.Context) (T, error)) {
+	router.Provide(genericToType[T](), scope, func(ctx context.Context) (any, error) {
+		return factory(ctx)
+	})
+}
+
+// Resolve returns the instance of T for the current request, it can only be called beneath a Route.
+func Resolve[T any](ctx context.Context) (T, error) {
+	instance, err := ResolveType(ctx, genericToType[T]())
+	if err != nil || instance == nil {
+		return *new(T), err
+	}
+
+	return instance.(T), nil
+}
+
+// ResolveType returns the instance of typ for the current request, it can only be called beneath a Route.
+func ResolveType(ctx context.Context, typ reflect.Type) (any, error) {
+	resolver, ok := ctx.Value(resolverKey{}).(*resolver)
+	if !ok {
+		return nil, errors.New("gotrac: services can only be resolved beneath a route")
+	}
+
+	return resolver.resolve(ctx, typ)
+}
+
+// Verify checks that the dependencies of all routes beneath router are provided.
+// Since sub-routers may be built before they are mounted, this can only be checked once the whole tree is built.
+// Call this after building the root Router, to detect missing providers on startup instead of the first request.
+func Verify(router chi.Routes) error {
+	missing := missingDependencies(router)
+	if len(missing) > 0 {
+		return errors.New(missingError(missing))
+	}
+
+	return nil
+}
+
+func missingDependencies(router chi.Routes) []reflect.Type {
+	var missing []reflect.Type
+	if verifier, ok := router.(dependencyVerifier); ok {
+		missing = append(missing, verifier.missingDependencies()...)
+	}
+
+	for _, route := range router.Routes() {
+		if route.SubRoutes != nil {
+			missing = append(missing, missingDependencies(route.SubRoutes)...)
+		}
+	}
+
+	return missing
+}
+
+// dependencyVerifier is implemented by routers that have a container
+type dependencyVerifier interface {
+	missingDependencies() []reflect.Type
+}
+
+// +++ Container +++
+
+type provider struct {
+	scope   Scope
+	factory Factory
+	// instances holds the singletons of this provider
+	instances *instances
+}
+
+// container holds the providers of a Router and links to the one of its parent
+type container struct {
+	parent    *container
+	providers map[reflect.Type]*provider
+
+	// dependencies holds the dependencies of the routes registered with this container
+	dependencies []reflect.Type
+
+	// started is set once the root Router of this container serves its first request, err is the result of Verify then
+	started atomic.Bool
+	once    sync.Once
+	err     error
+}
+
+func newContainer() *container {
+	return &container{providers: map[reflect.Type]*provider{}}
+}
+
+// provide registers a provider, which is only allowed before the root Router serves its first request,
+// since the providers are read without locking afterwards
+func (c *container) provide(typ reflect.Type, scope Scope, factory Factory) {
+	for current := c; current != nil; current = current.parent {
+		if current.started.Load() {
+			panic(fmt.Sprintf("gotrac: the provider for %s has to be registered before the root router serves the first request", typ))
+		}
+	}
+
+	c.providers[typ] = &provider{
+		scope:     scope,
+		factory:   factory,
+		instances: newInstances(),
+	}
+}
+
+func (c *container) lookup(typ reflect.Type) *provider {
+	for current := c; current != nil; current = current.parent {
+		if p, ok := current.providers[typ]; ok {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (c *container) require(dependencies []reflect.Type) {
+	c.dependencies = append(c.dependencies, dependencies...)
+}
+
+// missing returns the dependencies that are not provided by this container or its parents
+func (c *container) missing() []reflect.Type {
+	var missing []reflect.Type
+	for _, typ := range c.dependencies {
+		if c.lookup(typ) == nil {
+			missing = append(missing, typ)
+		}
+	}
+
+	return missing
+}
+
+// verify checks the dependencies of all routes beneath root once, when root serves its first request
+func (c *container) verify(root chi.Routes) error {
+	c.once.Do(func() {
+		c.started.Store(true)
+		c.err = Verify(root)
+		if c.err != nil {
+			log.Printf("%v, every request is answered with 500", c.err)
+		}
+	})
+
+	return c.err
+}
+
+// mount links the container to the one of the Router it is mounted on
+func (c *container) mount(parent *container) {
+	if c.parent == nil && c != parent {
+		c.parent = parent
+	}
+}
+
+func missingError(missing []reflect.Type) string {
+	names := make([]string, len(missing))
+	for i, typ := range missing {
+		names[i] = typ.String()
+	}
+
+	return "gotrac: no provider registered for " + strings.Join(names, ", ")
+}
+
+// +++ Resolution +++
+
+type resolverKey struct{}
+
+// resolver resolves the services for a single request
+type resolver struct {
+	container *container
+	request   *instances
+	route     *instances
+}
+
+func withResolver(ctx context.Context, resolver *resolver) context.Context {
+	return context.WithValue(ctx, resolverKey{}, resolver)
+}
+
+func requestInstances(ctx context.Context) *instances {
+	if resolver, ok := ctx.Value(resolverKey{}).(*resolver); ok {
+		return resolver.request
+	}
+
+	return nil
+}
+
+func (r *resolver) resolve(ctx context.Context, typ reflect.Type) (any, error) {
+	p := r.container.lookup(typ)
+	if p == nil {
+		return nil, fmt.Errorf("gotrac: no provider registered for %s", typ)
+	}
+
+	switch p.scope {
+	case Singleton:
+		return p.instances.get(ctx, typ, p.factory)
+	case PerRequest:
+		return r.request.get(ctx, typ, p.factory)
+	case PerRoute:
+		return r.route.get(ctx, typ, p.factory)
+	default:
+		return nil, fmt.Errorf("gotrac: unknown scope %d", p.scope)
+	}
+}
+
+// instances caches the created instances of a scope
+type instances struct {
+	mu        sync.Mutex
+	instances map[reflect.Type]any
+	closers   []io.Closer
+}
+
+func newInstances() *instances {
+	return &instances{instances: map[reflect.Type]any{}}
+}
+
+func (i *instances) get(ctx context.Context, typ reflect.Type, factory Factory) (any, error) {
+	i.mu.Lock()
+	instance, ok := i.instances[typ]
+	i.mu.Unlock()
+
+	if ok {
+		return instance, nil
+	}
+
+	// the factory is called without holding the lock, since it may resolve other services
+	created, err := factory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if instance, ok := i.instances[typ]; ok {
+		// created concurrently, discard ours
+		if closer, ok := created.(io.Closer); ok {
+			_ = closer.Close()
+		}
+
+		return instance, nil
+	}
+
+	i.instances[typ] = created
+	if closer, ok := created.(io.Closer); ok {
+		i.closers = append(i.closers, closer)
+	}
+
+	return created, nil
+}
+
+// close closes all instances in reverse order of their creation
+func (i *instances) close() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var errs []error
+	for j := len(i.closers) - 1; j >= 0; j-- {
+		errs = append(errs, i.closers[j].Close())
+	}
+
+	i.closers = nil
+	i.instances = map[reflect.Type]any{}
+	return errors.Join(errs...)
+}
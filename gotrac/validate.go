@@ -0,0 +1,364 @@
+package gotrac
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DiagnosticKind classifies a Diagnostic reported by Validate
+type DiagnosticKind string
+
+const (
+	// DuplicateRoute is reported if the same method and pattern (or patterns only differing in their parameter names) are registered twice
+	DuplicateRoute DiagnosticKind = "duplicate"
+	// ShadowedRoute is reported if a more specific pattern takes precedence over a route for some paths, e.g. /users/me over /users/{id}
+	ShadowedRoute DiagnosticKind = "shadowed"
+	// MountConflict is reported if a route is registered beneath the pattern of a router mounted beside it
+	MountConflict DiagnosticKind = "mount"
+	// ParamMismatch is reported if routes with the same pattern name their path parameters differently
+	ParamMismatch DiagnosticKind = "params"
+	// ConflictingRoute is reported if two routes of a ServeMux match some of the same paths, but neither is more specific,
+	// e.g. /a/{x}/b and /a/c/{y}
+	ConflictingRoute DiagnosticKind = "conflict"
+)
+
+// Severity returns how severe diagnostics of this kind are, only a ShadowedRoute is a Warning
+func (kind DiagnosticKind) Severity() Severity {
+	if kind == ShadowedRoute {
+		return Warning
+	}
+
+	return Error
+}
+
+// Severity of a Diagnostic, see Diagnostics.Err
+type Severity int
+
+const (
+	// Warning is reported for layouts that are usually intended, e.g. /users/me beside /users/{id}
+	Warning Severity = iota
+	// Error is reported for routes that cannot be served as intended
+	Error
+)
+
+// Diagnostic is a problem of the routes found by Validate
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Severity Severity
+	Method   string
+	// Pattern is the full pattern of the route the diagnostic is about
+	Pattern string
+	// Other is the full pattern of the conflicting route or mount
+	Other   string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return string(d.Kind) + ": " + d.Message
+}
+
+// Diagnostics are the problems found by Validate
+type Diagnostics []Diagnostic
+
+// Of returns the diagnostics of kind
+func (d Diagnostics) Of(kind DiagnosticKind) Diagnostics {
+	var result Diagnostics
+	for _, diagnostic := range d {
+		if diagnostic.Kind == kind {
+			result = append(result, diagnostic)
+		}
+	}
+
+	return result
+}
+
+// Errors returns the diagnostics with the Error severity
+func (d Diagnostics) Errors() Diagnostics {
+	var result Diagnostics
+	for _, diagnostic := range d {
+		if diagnostic.Severity == Error {
+			result = append(result, diagnostic)
+		}
+	}
+
+	return result
+}
+
+// Err returns an error listing all diagnostics with the Error severity, or nil if there are none.
+// Warnings like a ShadowedRoute are left out.
+func (d Diagnostics) Err() error {
+	errs := d.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(errs))
+	for _, diagnostic := range errs {
+		lines = append(lines, "\t"+diagnostic.String())
+	}
+
+	return errors.New("gotrac: the routes are conflicting:\n" + strings.Join(lines, "\n"))
+}
+
+// Validate checks the routes beneath router for conflicts that would only show at runtime:
+// duplicate routes, routes shadowed by more specific ones (only a Warning), routes registered beneath a sibling mount,
+// path parameters that are named differently by routes with the same pattern and routes of a ServeMux that conflict.
+// Call it after building the root Router, e.g. in a test:
+//
+//	if err := gotrac.Validate(router).Err(); err != nil {
+//		t.Fatal(err)
+//	}
+func Validate(router chi.Routes) Diagnostics {
+	v := &validator{}
+	v.walk(router, "")
+	v.compare()
+	return v.diagnostics
+}
+
+// registrar is implemented by routers that record every registered route, since the routes of chi only contain the last duplicate
+type registrar interface {
+	registrations() []registration
+}
+
+type registration struct {
+	method  string
+	pattern string
+}
+
+type validator struct {
+	routes      []validatedRoute
+	diagnostics Diagnostics
+}
+
+type validatedRoute struct {
+	method   string
+	pattern  string
+	segments []segment
+	// servemux is set for routes of a ServeMux, which does not prefer routes by the first differing segment like chi
+	servemux bool
+}
+
+func (v *validator) walk(router chi.Routes, prefix string) {
+	servemux := isServeMux(router)
+
+	if r, ok := router.(registrar); ok {
+		counts := map[registration]int{}
+		var order []registration
+		for _, registered := range r.registrations() {
+			if counts[registered] == 0 {
+				order = append(order, registered)
+			}
+
+			counts[registered]++
+		}
+
+		for _, registered := range order {
+			if n := counts[registered]; n > 1 {
+				pattern := prefix + registered.pattern
+				v.report(DuplicateRoute, registered.method, pattern, pattern, fmt.Sprintf("%s %s is registered %d times", registered.method, pattern, n))
+			}
+		}
+	}
+
+	var siblings []validatedRoute
+	var mounts []string
+	for _, route := range router.Routes() {
+		if route.SubRoutes != nil {
+			mount := prefix + strings.TrimSuffix(route.Pattern, "/*")
+			mounts = append(mounts, mount)
+			v.walk(route.SubRoutes, mount)
+			continue
+		}
+
+		for _, method := range slices.Sorted(maps.Keys(route.Handlers)) {
+			pattern := prefix + route.Pattern
+			siblings = append(siblings, validatedRoute{method: method, pattern: pattern, segments: segmentsOf(pattern), servemux: servemux})
+		}
+	}
+
+	// chi walks the routes sharing a node in random order, the diagnostics should not depend on it
+	slices.SortStableFunc(siblings, func(a validatedRoute, b validatedRoute) int {
+		return cmp.Or(strings.Compare(a.pattern, b.pattern), strings.Compare(a.method, b.method))
+	})
+
+	for _, mount := range mounts {
+		for _, sibling := range siblings {
+			if sibling.pattern == mount || strings.HasPrefix(sibling.pattern, mount+"/") {
+				v.report(MountConflict, sibling.method, sibling.pattern, mount+"/*",
+					fmt.Sprintf("%s %s is registered beside the mount %s/*, which serves the other paths beneath it", sibling.method, sibling.pattern, mount))
+			}
+		}
+	}
+
+	v.routes = append(v.routes, siblings...)
+}
+
+// compare reports the conflicts between all pairs of routes
+func (v *validator) compare() {
+	mismatched := map[[2]string]bool{}
+	for i, a := range v.routes {
+		for _, b := range v.routes[i+1:] {
+			overlap, winner, mixed := compareSegments(a.segments, b.segments)
+			sameMethod := a.method == b.method || a.method == "*" || b.method == "*"
+			if overlap && winner == 0 && sameMethod {
+				v.report(DuplicateRoute, b.method, b.pattern, a.pattern, fmt.Sprintf("%s %s matches the same paths as %s", b.method, b.pattern, a.pattern))
+				continue
+			}
+
+			pair := [2]string{a.pattern, b.pattern}
+			if !namesMatch(a.segments, b.segments) && !mismatched[pair] {
+				mismatched[pair] = true
+				v.report(ParamMismatch, b.method, b.pattern, a.pattern, fmt.Sprintf("%s names its path parameters differently than %s", b.pattern, a.pattern))
+			}
+
+			if !overlap || !sameMethod {
+				continue
+			}
+
+			if mixed && a.servemux && b.servemux {
+				v.report(ConflictingRoute, b.method, b.pattern, a.pattern, fmt.Sprintf("%s %s and %s match some of the same paths, but neither is more specific", b.method, b.pattern, a.pattern))
+				continue
+			}
+
+			if winner < 0 {
+				v.report(ShadowedRoute, b.method, b.pattern, a.pattern, fmt.Sprintf("%s %s is shadowed by %s for the paths both match", b.method, b.pattern, a.pattern))
+			} else {
+				v.report(ShadowedRoute, a.method, a.pattern, b.pattern, fmt.Sprintf("%s %s is shadowed by %s for the paths both match", a.method, a.pattern, b.pattern))
+			}
+		}
+	}
+}
+
+func (v *validator) report(kind DiagnosticKind, method string, pattern string, other string, message string) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{Kind: kind, Severity: kind.Severity(), Method: method, Pattern: pattern, Other: other, Message: message})
+}
+
+// isServeMux reports whether router (or the one of a controller) is a ServeMux
+func isServeMux(router chi.Routes) bool {
+	if controller, ok := router.(*controllerImpl); ok {
+		router = controller.handler
+	}
+
+	_, ok := router.(*ServeMux)
+	return ok
+}
+
+// +++ Segments +++
+
+type segmentKind int
+
+// the kinds are ordered by their precedence
+const (
+	staticSegment segmentKind = iota
+	paramSegment
+	wildcardSegment
+)
+
+type segment struct {
+	kind   segmentKind
+	value  string // the static value or the name of the parameter
+	regexp *regexp.Regexp
+}
+
+// segmentsOf splits a chi or http.ServeMux pattern into its segments
+func segmentsOf(pattern string) []segment {
+	var segments []segment
+	for _, part := range strings.Split(strings.TrimPrefix(pattern, "/"), "/") {
+		switch {
+		case part == "*" || (strings.HasPrefix(part, "{") && strings.HasSuffix(part, "...}")):
+			segments = append(segments, segment{kind: wildcardSegment})
+		case part == "{$}":
+			segments = append(segments, segment{kind: staticSegment})
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") && strings.Count(part, "{") == 1:
+			name, expression, _ := strings.Cut(strings.Trim(part, "{}"), ":")
+			param := segment{kind: paramSegment, value: name}
+			if expression != "" {
+				param.regexp, _ = regexp.Compile("^(?:" + expression + ")$")
+			}
+
+			segments = append(segments, param)
+		case strings.Contains(part, "{"):
+			// e.g. {name}.json is treated like a parameter without a name
+			segments = append(segments, segment{kind: paramSegment})
+		default:
+			segments = append(segments, segment{kind: staticSegment, value: part})
+		}
+	}
+
+	return segments
+}
+
+// compareSegments reports whether a path can match both a and b and which one takes precedence (-1 for a, 1 for b, 0 if neither)
+// by the first differing segment like chi, mixed is set if a later segment prefers the other one.
+func compareSegments(a []segment, b []segment) (overlap bool, winner int, mixed bool) {
+	prefer := func(x segmentKind, y segmentKind) {
+		if winner == 0 {
+			winner = precedence(x, y)
+		} else if winner != precedence(x, y) {
+			mixed = true
+		}
+	}
+
+	for i := 0; ; i++ {
+		if i == len(a) || i == len(b) {
+			return len(a) == len(b), winner, mixed
+		}
+
+		x, y := a[i], b[i]
+		if x.kind == wildcardSegment || y.kind == wildcardSegment {
+			if x.kind != y.kind {
+				prefer(x.kind, y.kind)
+			}
+
+			return true, winner, mixed
+		}
+
+		switch {
+		case x.kind == staticSegment && y.kind == staticSegment:
+			if x.value != y.value {
+				return false, 0, false
+			}
+		case x.kind != y.kind:
+			static, param := x, y
+			if x.kind == paramSegment {
+				static, param = y, x
+			}
+
+			if param.regexp != nil && !param.regexp.MatchString(static.value) {
+				return false, 0, false
+			}
+
+			prefer(x.kind, y.kind)
+		}
+	}
+}
+
+// namesMatch reports whether the parameters of a and b are named the same, as long as their patterns are equal
+func namesMatch(a []segment, b []segment) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		if x.kind != y.kind || (x.kind == staticSegment && x.value != y.value) {
+			return true
+		}
+
+		if x.kind == paramSegment && x.value != "" && y.value != "" && x.value != y.value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func precedence(a segmentKind, b segmentKind) int {
+	if a < b {
+		return -1
+	}
+
+	return 1
+}
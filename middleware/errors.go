@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5/middleware"
 	"net/http"
 )
@@ -11,6 +12,7 @@ import (
 // It uses a global cache with the request id as a key, it therefore requires chi`s RequestId middleware!
 //
 // If errors are present a 500 code will be returned and the errors encoded as json.
+// If the first error is (or wraps) a StatusError its status code is used instead.
 //
 // The GetErrors function and the Errors interface can be used without this middleware (only requiring chi`s RequestId)
 // so you can write your own error handler!
@@ -23,7 +25,7 @@ func ErrorMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		errs := make([]string, len(errors))
+		errs := make([]string, 0, len(errors))
 		for _, err := range errors {
 			errs = append(errs, err.Error())
 		}
@@ -38,7 +40,8 @@ func ErrorMiddleware(next http.Handler) http.Handler {
 			panic(err)
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusOf(errors[0]))
 		_, err = w.Write(js)
 		if err != nil {
 			panic(err)
@@ -46,6 +49,34 @@ func ErrorMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// StatusError is an error that also determines the HTTP status code returned by the ErrorMiddleware.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// Error wraps err to a StatusError, so the ErrorMiddleware responds with status instead of 500.
+func Error(status int, err error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+func statusOf(err error) int {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Status
+	}
+
+	return http.StatusInternalServerError
+}
+
 // Errors can be used to read and add errors to the error cache for this request!
 //
 // This requires only chi`s RequestId middleware, while you can use the ErrorMiddleware to handle the returned errors
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"github.com/swaggest/openapi-go/openapi31"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type PingController struct {
+	_ struct{} `name:"Ping" description:"Ping pong"`
+}
+
+func (c *PingController) Get(writer http.ResponseWriter, request *http.Request) {
+	pong(writer, request)
+}
+
+func (c *PingController) PostParity() http.Handler {
+	return gocart.IO(gocart.Json[PingRequest](), gocart.Json[PongResponse](), ping)
+}
+
+func (c *PingController) GetByN(writer http.ResponseWriter, request *http.Request) {
+	_, _ = writer.Write([]byte(request.PathValue("n")))
+}
+
+func (c *PingController) Paths() map[string]string {
+	return map[string]string{"GetByN": "/{n}"}
+}
+
+func (c *PingController) Describe(method string, info *goflag.EndpointInformation) {
+	info.WithSummary("PingController." + method)
+
+	if method == "GetByN" {
+		info.WithInput(gotrac.None[PingPath]())
+	}
+}
+
+type PingPath struct {
+	N int `path:"n"`
+}
+
+func TestController(t *testing.T) {
+	router := gotrac.Default()
+	router.Mount("/ping", gotrac.MustRegister(&PingController{}))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping/42", nil))
+	if body := recorder.Body.String(); body != "42" {
+		t.Errorf("expected %q, got %q", "42", body)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spec.Tags) != 1 || spec.Tags[0].Name != "Ping" {
+		t.Fatalf("expected a single tag Ping, got %v", spec.Tags)
+	}
+
+	for path, method := range map[string]string{"/ping/": http.MethodGet, "/ping/parity": http.MethodPost, "/ping/{n}": http.MethodGet} {
+		operation := operationOf(spec, method, path)
+		if operation == nil {
+			t.Errorf("missing operation %s %s", method, path)
+			continue
+		}
+
+		if len(operation.Tags) != 1 || operation.Tags[0] != "Ping" {
+			t.Errorf("%s %s: expected tag Ping, got %v", method, path, operation.Tags)
+		}
+	}
+}
+
+// operationOf returns the operation of the spec or nil if it does not exist
+func operationOf(spec *gocrew.OpenApi31Spec, method string, path string) *openapi31.Operation {
+	item, ok := spec.Paths.MapOfPathItemValues[path]
+	if !ok {
+		return nil
+	}
+
+	return map[string]*openapi31.Operation{
+		http.MethodGet:     item.Get,
+		http.MethodPut:     item.Put,
+		http.MethodPost:    item.Post,
+		http.MethodDelete:  item.Delete,
+		http.MethodOptions: item.Options,
+		http.MethodHead:    item.Head,
+		http.MethodPatch:   item.Patch,
+		http.MethodTrace:   item.Trace,
+	}[method]
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	router := gotrac.NewRouter().WithDefaults(func(defaults *goflag.EndpointDefaults) {
+		defaults.WithCORS(goflag.NewCORS("https://app.example.com").WithHeaders("Content-Type").WithMaxAge(time.Hour))
+	})
+	router.Use(gotrac.CORSMiddleware)
+
+	router.Get("/items", pong)
+	router.Get("/teapot", pong)
+	router.Options("/teapot", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	router.Delete("/items", pong).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithCORS(goflag.NewCORS("https://admin.example.com").WithCredentials(true))
+	})
+
+	serve := func(method string, origin string, requested string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/items", nil)
+		request.Header.Set("Origin", origin)
+		if requested != "" {
+			request.Header.Set("Access-Control-Request-Method", requested)
+			request.Header.Set("Access-Control-Request-Headers", "content-type")
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// DELETE is not advertised, since its policy rejects the origin
+	recorder := serve(http.MethodOptions, "https://app.example.com", http.MethodGet)
+	header := recorder.Header()
+	if recorder.Code != http.StatusNoContent || header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		header.Get("Access-Control-Allow-Methods") != "GET, HEAD" || header.Get("Access-Control-Max-Age") != "3600" {
+		t.Errorf("unexpected preflight response %d %v", recorder.Code, header)
+	}
+
+	// the route overrides the policy of the router
+	if header = serve(http.MethodOptions, "https://app.example.com", http.MethodDelete).Header(); header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("the origin must not be allowed to delete, got %v", header)
+	}
+
+	if header = serve(http.MethodDelete, "https://admin.example.com", "").Header(); header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("unexpected response headers %v", header)
+	}
+
+	if header = serve(http.MethodGet, "https://evil.example.com", "").Header(); header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("the origin must not be allowed, got %v", header)
+	}
+
+	// explicit OPTIONS routes and unknown paths are not answered as preflights
+	for target, expected := range map[string]int{"/teapot": http.StatusTeapot, "/unknown": http.StatusNotFound} {
+		request := httptest.NewRequest(http.MethodOptions, target, nil)
+		request.Header.Set("Origin", "https://app.example.com")
+		request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != expected {
+			t.Errorf("expected %d for the preflight of %s, got %d", expected, target, recorder.Code)
+		}
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extension, ok := operationOf(spec, http.MethodDelete, "/items").MapOfAnything["x-cors"].(map[string]any)
+	if !ok || extension["credentials"] != true {
+		t.Errorf("unexpected x-cors extension %v", extension)
+	}
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCurrentRoute(t *testing.T) {
+	var matched *gotrac.MatchedRoute
+
+	router := gotrac.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			matched = gotrac.CurrentRoute(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	users := gotrac.NewRouter()
+	users.MethodFunc(http.MethodGet, "/{id}", pong).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithSummary("Get a user")
+	})
+
+	router.Mount("/users", gotrac.WithName("Users", users))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if matched == nil {
+		t.Fatal("expected the current route to be set")
+	}
+
+	if matched.Method != http.MethodGet || matched.Pattern != "/users/{id}" || matched.Controller.Info().Name != "Users" || matched.Handler == nil || matched.Info().Summary != "Get a user" {
+		t.Errorf("unexpected route %+v", matched)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if matched != nil {
+		t.Errorf("expected no route for an unknown path, got %+v", matched)
+	}
+
+	// routes registered after the first request are found as well
+	users.MethodFunc(http.MethodDelete, "/{id}", pong).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithSummary("Delete a user")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/42", nil))
+	if matched == nil || matched.Info() == nil || matched.Info().Summary != "Delete a user" {
+		t.Errorf("unexpected route %+v", matched)
+	}
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultStack(t *testing.T) {
+	if _, err := gotrac.NewDefault(gotrac.RequestID(false)); err == nil {
+		t.Error("the ErrorMiddleware must require the RequestID middleware")
+	}
+
+	if _, err := gotrac.NewDefault(gotrac.RequestID(false), gotrac.Errors(false)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	served := false
+	router := gotrac.Default(gotrac.Production(), gotrac.RealIP(true), gotrac.Logger(nil), gotrac.Middlewares(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = true
+			next.ServeHTTP(w, r)
+		})
+	}))
+	router.Get("/ping", pong)
+
+	// RedirectSlashes, RealIP, RequestID, Recoverer, Timeout, BodyLimit, ErrorMiddleware and the custom one
+	if count := len(router.Middlewares()); count != 8 {
+		t.Errorf("expected 8 middlewares, got %d", count)
+	}
+
+	// RealIP has to be enabled explicitly
+	if count := len(gotrac.Default(gotrac.Production(), gotrac.Logger(nil)).Middlewares()); count != 6 {
+		t.Errorf("expected 6 middlewares without RealIP, got %d", count)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if recorder.Code != http.StatusOK || !served {
+		t.Errorf("unexpected response %d", recorder.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	router := gotrac.NewRouter().WithDefaults(func(defaults *goflag.EndpointDefaults) {
+		defaults.WithTags("api").
+			WithSecurity("bearer").
+			WithResponse(http.StatusUnauthorized, "Missing token", nil)
+	})
+
+	var legacy, current gotrac.Route
+	router.Group(func(r gotrac.Router) {
+		r.WithDefaults(func(defaults *goflag.EndpointDefaults) {
+			defaults.WithDeprecated(true)
+		})
+
+		legacy = r.MethodFunc(http.MethodGet, "/legacy", pong)
+		current = r.MethodFunc(http.MethodGet, "/current", pong).WithInfo(func(info *goflag.EndpointInformation) {
+			info.WithDeprecated(false)
+		})
+	})
+
+	var public gotrac.Route
+	router.Route("/public", func(r gotrac.Router) {
+		r.WithDefaults(func(defaults *goflag.EndpointDefaults) {
+			defaults.Security = []goflag.Security{}
+		})
+
+		public = r.MethodFunc(http.MethodGet, "/", pong).WithInfo(func(info *goflag.EndpointInformation) {
+			info.WithTags("public").WithResponse(http.StatusUnauthorized, "Never", nil)
+		})
+	})
+
+	info := legacy.Info()
+	if !info.IsDeprecated() || !slices.Equal(info.Tags, []string{"api"}) || len(info.Security) != 1 || len(info.Responses) != 1 {
+		t.Errorf("unexpected information of the legacy route %+v", info)
+	}
+
+	if current.Info().IsDeprecated() {
+		t.Error("the route must override the deprecation of the group")
+	}
+
+	info = public.Info()
+	if info.IsDeprecated() || !slices.Equal(info.Tags, []string{"api", "public"}) || len(info.Security) != 0 || info.Responses[0].Description != "Never" {
+		t.Errorf("unexpected information of the public route %+v", info)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation := operationOf(spec, http.MethodGet, "/legacy")
+	if operation.Deprecated == nil || !*operation.Deprecated || len(operation.Security) != 1 || !slices.Contains(operation.Tags, "api") {
+		t.Errorf("unexpected legacy operation %+v", operation)
+	}
+
+	if _, ok := operation.Responses.MapOfResponseOrReferenceValues["401"]; !ok {
+		t.Errorf("expected the inherited 401 response, got %v", operation.Responses.MapOfResponseOrReferenceValues)
+	}
+
+	if operation = operationOf(spec, http.MethodGet, "/public/"); len(operation.Security) != 0 {
+		t.Errorf("expected no security on the public operation, got %v", operation.Security)
+	}
+}
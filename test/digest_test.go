@@ -0,0 +1,53 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/ping", gocart.IO(gocart.Json[PingRequest](), gocart.Json[PongResponse](), ping).
+		WithVerifier(gocart.ContentDigest(true)).
+		WithDigest(gocart.SHA256))
+
+	digest := func(body string) string {
+		sum := sha256.Sum256([]byte(body))
+		return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/ping", strings.NewReader(`{"n":2}`))
+	request.Header.Set("Content-Digest", digest(`{"n":2}`))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if got, expected := recorder.Header().Get("Content-Digest"), digest(recorder.Body.String()); got != expected {
+		t.Errorf("expected Content-Digest %q, got %q", expected, got)
+	}
+
+	for name, header := range map[string]string{
+		"mismatch": digest(`{"n":3}`),
+		"missing":  "",
+	} {
+		request = httptest.NewRequest(http.MethodPost, "/ping", strings.NewReader(`{"n":2}`))
+		request.Header.Set("Content-Digest", header)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDownload(t *testing.T) {
+	files := fstest.MapFS{
+		"docs/hello wörld.txt": {Data: []byte("hello world")},
+	}
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/files/*", gocart.Dir(files))
+
+	request := httptest.NewRequest(http.MethodGet, "/files/docs/hello%20w%C3%B6rld.txt", nil)
+	request.Header.Set("Range", "bytes=6-")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Fatalf("expected status %d, got %d", http.StatusPartialContent, recorder.Code)
+	}
+
+	if body := recorder.Body.String(); body != "world" {
+		t.Errorf("expected body %q, got %q", "world", body)
+	}
+
+	expected := `inline; filename="hello w_rld.txt"; filename*=UTF-8''hello%20w%C3%B6rld.txt`
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != expected {
+		t.Errorf("expected Content-Disposition %q, got %q", expected, disposition)
+	}
+
+	if typ := recorder.Header().Get("Content-Type"); typ != "text/plain; charset=utf-8" {
+		t.Errorf("expected Content-Type text/plain, got %q", typ)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/missing.txt", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type Counter struct {
+	N int
+}
+
+type Transaction struct {
+	Closed bool
+}
+
+func (t *Transaction) Close() error {
+	t.Closed = true
+	return nil
+}
+
+type PingDeps struct {
+	Counter     *Counter
+	Transaction *Transaction
+}
+
+func TestInject(t *testing.T) {
+	var transactions []*Transaction
+
+	router := gotrac.Default()
+	gotrac.Provide(router, gotrac.Singleton, func(_ context.Context) (*Counter, error) {
+		return &Counter{}, nil
+	})
+	gotrac.Provide(router, gotrac.PerRequest, func(_ context.Context) (*Transaction, error) {
+		transaction := &Transaction{}
+		transactions = append(transactions, transaction)
+		return transaction, nil
+	})
+
+	router.Route("/api", func(r gotrac.Router) {
+		r.Method(http.MethodGet, "/ping", gocart.Inject(nil, gocart.Json[PongResponse](), func(_ *gocart.Request[any], _ gocart.HeaderWriter, deps PingDeps) (*PongResponse, error) {
+			deps.Counter.N++
+			return &PongResponse{Pong: deps.Counter.N%2 == 0 && !deps.Transaction.Closed}, nil
+		}))
+	})
+
+	for _, expected := range []string{`{"pong":false}`, `{"pong":true}`} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+
+		if body := recorder.Body.String(); body != expected {
+			t.Errorf("expected %q, got %q", expected, body)
+		}
+	}
+
+	if len(transactions) != 2 || !transactions[0].Closed || !transactions[1].Closed {
+		t.Errorf("expected 2 closed transactions, got %v", transactions)
+	}
+
+	if err := gotrac.Verify(router); err != nil {
+		t.Error(err)
+	}
+
+	router.Route("/sub", func(r gotrac.Router) {
+		r.Method(http.MethodGet, "/missing", gocart.Inject(nil, nil, func(_ *gocart.Request[any], _ gocart.HeaderWriter, _ *PingRequest) (*any, error) {
+			return nil, nil
+		}))
+	})
+
+	if err := gotrac.Verify(router); err == nil {
+		t.Error("expected an error for a missing provider")
+	}
+}
+
+func TestInjectMissing(t *testing.T) {
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/counter", gocart.Inject(nil, gocart.Json[Counter](), func(_ *gocart.Request[any], _ gocart.HeaderWriter, counter *Counter) (*Counter, error) {
+		return counter, nil
+	}))
+
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/counter", nil))
+		return recorder.Code
+	}
+
+	if err := gotrac.Verify(router); err == nil {
+		t.Error("expected Verify to report the missing provider")
+	}
+
+	// every request fails if the provider is missing
+	for range 2 {
+		if code := serve(); code != http.StatusInternalServerError {
+			t.Errorf("expected 500 for a missing provider, got %d", code)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a provider after the first request to panic")
+		}
+	}()
+
+	gotrac.Provide(router.With(), gotrac.Singleton, func(_ context.Context) (*Counter, error) {
+		return &Counter{}, nil
+	})
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	router := gotrac.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Mount("/ping", gotrac.MustRegister(&PingController{}))
+	router.With(middleware.NoCache).Method(http.MethodGet, "/items", gocart.O(gocart.Json[Item](), func(*gocart.Request[struct{}], gocart.HeaderWriter) (*Item, error) {
+		return &Item{}, nil
+	})).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithSummary("List items")
+	})
+	router.Method(http.MethodGet, "/debug/routes", gocrew.InspectHandler(router))
+
+	table, err := gocrew.Inspect(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string]gocrew.RouteEntry{}
+	for _, entry := range table {
+		entries[entry.Method+" "+entry.Pattern] = entry
+	}
+
+	items := entries["GET /items"]
+	if items.Middlewares != 2 || items.Summary != "List items" || !strings.Contains(items.Output, "test.Item") || items.Hidden {
+		t.Errorf("unexpected entry %+v", items)
+	}
+
+	if ping := entries["POST /ping/parity"]; ping.Controller != "Ping" || !strings.Contains(ping.Input, "PingRequest") {
+		t.Errorf("unexpected entry %+v", ping)
+	}
+
+	if !entries["GET /debug/routes"].Hidden {
+		t.Error("the inspection endpoint must be hidden")
+	}
+
+	if text := table.String(); !strings.HasPrefix(text, "METHOD ") || !strings.Contains(text, "List items") {
+		t.Errorf("unexpected table\n%s", text)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/routes?format=json", nil))
+
+	var served gocrew.RouteTable
+	if err = json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || len(served) != len(table) {
+		t.Errorf("unexpected json %q (%v)", recorder.Body.String(), err)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	if !strings.Contains(recorder.Body.String(), "<td>/items</td>") {
+		t.Errorf("unexpected html %q", recorder.Body.String())
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if operationOf(spec, http.MethodGet, "/debug/routes") != nil {
+		t.Error("the inspection endpoint must not be documented")
+	}
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"github.com/benni-tec/gocart/middleware"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Secret struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (s *Secret) Redact() {
+	s.Password = "***"
+}
+
+type Redactable interface {
+	Redact()
+}
+
+func TestInterceptor(t *testing.T) {
+	var order []string
+
+	audit := func(name string) gocart.Interceptor {
+		return &gocart.Hooks[any, any]{
+			Before: func(_ *gocart.Request[any], _ gocart.HeaderWriter) (*any, error) {
+				order = append(order, "before "+name)
+				return nil, nil
+			},
+			After: func(_ *gocart.Request[any], output *any, err error) (*any, error) {
+				order = append(order, "after "+name)
+				return output, err
+			},
+		}
+	}
+
+	router := gotrac.Default()
+	router.Use(gocart.Intercept(audit("router"), gocart.After(func(_ *gocart.Request[any], output *Redactable, err error) (*Redactable, error) {
+		if output != nil {
+			(*output).Redact()
+		}
+
+		return output, err
+	})))
+
+	router.Method(http.MethodPost, "/secret", gocart.IO(gocart.Json[Secret](), gocart.Json[Secret](), func(request *gocart.Request[Secret], _ gocart.HeaderWriter) (*Secret, error) {
+		order = append(order, "handler")
+		return request.Body(), nil
+	}).WithInterceptor(audit("cart"), gocart.Before(func(request *gocart.Request[Secret], _ gocart.HeaderWriter) (*Secret, error) {
+		if request.Body().Name == "root" {
+			return nil, middleware.Error(http.StatusForbidden, errors.New("forbidden"))
+		}
+
+		return nil, nil
+	})))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/secret", strings.NewReader(`{"name":"alice","password":"1234"}`)))
+
+	if body := recorder.Body.String(); body != `{"name":"alice","password":"***"}` {
+		t.Errorf("expected redacted output, got %q", body)
+	}
+
+	expected := []string{"before router", "before cart", "handler", "after cart", "after router"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+
+	order = nil
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/secret", strings.NewReader(`{"name":"root","password":"1234"}`)))
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+
+	expected = []string{"before router", "before cart", "after cart", "after router"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+type tenantKey struct{}
+
+func TestInterceptorFunc(t *testing.T) {
+	// changes to the request of Hooks with an adapted type are passed on to the CartFunc
+	tenant := gocart.Before(func(request *gocart.Request[any], _ gocart.HeaderWriter) (*any, error) {
+		request.Request = *request.WithContext(context.WithValue(request.Context(), tenantKey{}, "acme"))
+		request.Header.Set("X-Tenant", "acme")
+		return nil, nil
+	})
+
+	timing := gocart.InterceptorFunc(func(call *gocart.Call) (any, error) {
+		call.Writer.Header().Set("X-Intercepted", "true")
+		return call.Proceed()
+	})
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/tenant", gocart.O(gocart.Json[string](), func(request *gocart.Request[struct{}], _ gocart.HeaderWriter) (*string, error) {
+		value := fmt.Sprint(request.Context().Value(tenantKey{})) + " " + request.Header.Get("X-Tenant")
+		return &value, nil
+	}).WithInterceptor(timing, tenant))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/tenant", nil))
+
+	if body := recorder.Body.String(); body != `"acme acme"` {
+		t.Errorf("expected the changed request, got %q", body)
+	}
+
+	if recorder.Header().Get("X-Intercepted") != "true" {
+		t.Error("expected the custom interceptor to be called")
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMethods(t *testing.T) {
+	router := gotrac.NewRouter()
+	router.Get("/items", pong)
+	router.Post("/items", pong)
+	router.Get("/stream", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("chunk"))
+		if err := http.NewResponseController(writer).Flush(); err != nil {
+			t.Errorf("the HEAD response must be flushable, got %v", err)
+		}
+	})
+	router.Route("/items/{id}", func(r gotrac.Router) {
+		r.Get("/", pong)
+		r.Delete("/", pong)
+		r.Options("/", func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusTeapot)
+		})
+	})
+	router.WithPreflight(func(header http.Header, _ *http.Request, allowed []string) {
+		header.Set("Access-Control-Allow-Origin", "*")
+	})
+
+	serve := func(method string, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	recorder := serve(http.MethodOptions, "/items")
+	if recorder.Code != http.StatusNoContent || recorder.Header().Get("Allow") != "GET, HEAD, POST, OPTIONS" {
+		t.Errorf("unexpected OPTIONS response %d %q", recorder.Code, recorder.Header().Get("Allow"))
+	}
+
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("the preflight headers are missing")
+	}
+
+	if recorder = serve(http.MethodOptions, "/items/1"); recorder.Code != http.StatusTeapot {
+		t.Errorf("the explicit OPTIONS route must take precedence, got %d", recorder.Code)
+	}
+
+	recorder = serve(http.MethodHead, "/items")
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Errorf("unexpected HEAD response %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	if recorder = serve(http.MethodHead, "/stream"); recorder.Body.Len() != 0 || !recorder.Flushed {
+		t.Errorf("unexpected HEAD response %q flushed=%t", recorder.Body.String(), recorder.Flushed)
+	}
+
+	recorder = serve(http.MethodPut, "/items/1")
+	if recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Values("Allow")[0] != "GET, HEAD, DELETE, OPTIONS" {
+		t.Errorf("unexpected 405 response %d %q", recorder.Code, recorder.Header().Values("Allow"))
+	}
+
+	router.MethodNotAllowed(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusConflict)
+	})
+	for _, path := range []string{"/items", "/items/1"} {
+		if recorder = serve(http.MethodPut, path); recorder.Code != http.StatusConflict || recorder.Header().Get("Allow") == "" {
+			t.Errorf("unexpected custom 405 response for %s %d %q", path, recorder.Code, recorder.Header().Get("Allow"))
+		}
+	}
+
+	if recorder = serve(http.MethodOptions, "/unknown"); recorder.Code != http.StatusNotFound {
+		t.Errorf("unknown paths must not be answered, got %d", recorder.Code)
+	}
+}
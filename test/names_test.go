@@ -0,0 +1,79 @@
+package test
+
+import (
+	"errors"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ItemPath struct {
+	ID     int    `path:"id"`
+	Expand string `query:"expand"`
+}
+
+func TestNames(t *testing.T) {
+	see := gocart.Redirect(http.StatusSeeOther)
+
+	router := gotrac.Default()
+	router.Route("/api", func(r gotrac.Router) {
+		r.Route("/items", func(r gotrac.Router) {
+			documented := func(info *goflag.EndpointInformation) {
+				info.WithInput(gotrac.None[ItemPath]())
+			}
+
+			r.MethodFunc(http.MethodGet, "/{id:[0-9]+}", pong).WithName("getItem").WithInfo(documented)
+			r.MethodFunc(http.MethodDelete, "/{id:[0-9]+}", pong).WithInfo(documented)
+		})
+	})
+
+	router.Method(http.MethodGet, "/latest", gocart.O(gocart.Responses(see), func(request *gocart.Request[struct{}], _ gocart.HeaderWriter) (*gocart.Result, error) {
+		return see.AtRoute(request.Context(), "getItem", ItemPath{ID: 7}, nil)
+	}))
+
+	url, err := gotrac.Reverse(router, "getItem", ItemPath{ID: 42, Expand: "owner"})
+	if err != nil || url != "/api/items/42?expand=owner" {
+		t.Errorf("unexpected url %q (%v)", url, err)
+	}
+
+	if _, err = gotrac.Reverse(router, "unknown", nil); !errors.Is(err, gotrac.ErrUnknownRoute) {
+		t.Errorf("expected ErrUnknownRoute, got %v", err)
+	}
+
+	if _, err = gotrac.Reverse(router, "getItem", map[string]any{"expand": "owner"}); !errors.Is(err, gotrac.ErrMissingParam) {
+		t.Errorf("expected ErrMissingParam, got %v", err)
+	}
+
+	// zero values are kept for path parameters, but omitted from the query
+	url, err = gotrac.Reverse(router, "getItem", map[string]any{"id": 0, "expand": ""})
+	if err != nil || url != "/api/items/0" {
+		t.Errorf("unexpected url %q (%v)", url, err)
+	}
+
+	if _, err = gotrac.Reverse(router, "getItem", map[string]any{"id": "abc"}); !errors.Is(err, gotrac.ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/latest", nil))
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/api/items/7" {
+		t.Errorf("unexpected redirect %d to %q", recorder.Code, recorder.Header().Get("Location"))
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := operationOf(spec, http.MethodGet, "/api/items/{id}").ID; id == nil || *id != "getItem" {
+		t.Errorf("expected the operationId getItem, got %v", id)
+	}
+
+	if id := operationOf(spec, http.MethodDelete, "/api/items/{id}").ID; id == nil || *id != "deleteApiItemsId" {
+		t.Errorf("expected the operationId deleteApiItemsId, got %v", id)
+	}
+}
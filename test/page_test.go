@@ -0,0 +1,71 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type ItemQuery struct {
+	gocart.OffsetParams
+	Prefix string `query:"prefix"`
+}
+
+func TestPage(t *testing.T) {
+	var items []Item
+	for i := range 5 {
+		items = append(items, Item{Name: "item" + strconv.Itoa(i)})
+	}
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/items", gocart.O(gocart.Json[gocart.Page[Item]](), func(request *gocart.Request[ItemQuery], _ gocart.HeaderWriter) (*gocart.Page[Item], error) {
+		return gocart.PageSeq(slices.Values(items), request.Body().OffsetParams), nil
+	}))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items?limit=2&offset=2&prefix=x", nil))
+
+	var page gocart.Page[Item]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Items) != 2 || page.Items[0].Name != "item2" || page.Next != "4" || page.Prev != "0" {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	expected := `</items?limit=2&offset=4&prefix=x>; rel="next", </items?limit=2&offset=0&prefix=x>; rel="prev"`
+	if link := recorder.Header().Get("Link"); link != expected {
+		t.Errorf("unexpected link header %q", link)
+	}
+
+	last := gocart.PageOf(items, gocart.OffsetParams{Limit: 2, Offset: 4})
+	if len(last.Items) != 1 || last.Next != "" || *last.Total != 5 {
+		t.Errorf("unexpected last page %+v", last)
+	}
+
+	cursor := gocart.CursorPage(slices.Values(items), gocart.CursorParams{Limit: 3}, func(item Item) string { return item.Name })
+	if cursor.Next != "item2" {
+		t.Errorf("expected next cursor item2, got %q", cursor.Next)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := spec.Components.Schemas["PageOfItem"]; !ok {
+		t.Errorf("expected a PageOfItem schema, got %v", spec.Components.Schemas)
+	}
+
+	operation := operationOf(spec, http.MethodGet, "/items")
+	if operation == nil || len(operation.Parameters) != 3 {
+		t.Errorf("expected limit, offset and prefix parameters, got %+v", operation)
+	}
+}
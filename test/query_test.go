@@ -0,0 +1,107 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type Ticket struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+type TicketQuery struct {
+	gocart.Query[Ticket]
+}
+
+func TestQuery(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Title: "first", Status: "open"},
+		{ID: 2, Title: "second", Status: "closed"},
+		{ID: 3, Title: "third", Status: "open"},
+	}
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/tickets", gocart.O(gocart.Json[[]Ticket](), func(request *gocart.Request[TicketQuery], _ gocart.HeaderWriter) (*[]Ticket, error) {
+		result := request.Body().Apply(tickets)
+		return &result, nil
+	}))
+
+	router.Method(http.MethodGet, "/large", gocart.O(gocart.Json[[]Ticket](), func(*gocart.Request[TicketQuery], gocart.HeaderWriter) (*[]Ticket, error) {
+		return &[]Ticket{{ID: 1<<53 + 1, Title: "<b>large</b>", Status: "open"}}, nil
+	}))
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	recorder := serve("/tickets?sort=-id&filter[status]=open&fields=id,title")
+	if body := recorder.Body.String(); body != `[{"id":3,"title":"third"},{"id":1,"title":"first"}]` {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	// the selected fields keep their order, escaping and precision
+	if body := serve("/large?fields=status,title,id").Body.String(); body != `[{"id":9007199254740993,"title":"\u003cb\u003elarge\u003c/b\u003e","status":"open"}]` {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if body := serve("/tickets?filter[id][in]=1,2&filter[id][gt]=1").Body.String(); body != `[{"id":2,"title":"second","status":"closed"}]` {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	for _, target := range []string{"/tickets?sort=unknown", "/tickets?filter[id][like]=1", "/tickets?filter[id]=abc", "/tickets?fields=secret"} {
+		if recorder := serve(target); recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", target, recorder.Code)
+		}
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation := operationOf(spec, http.MethodGet, "/tickets")
+	if operation == nil || len(operation.Parameters) != 3 {
+		t.Fatalf("expected sort, filter and fields parameters, got %+v", operation)
+	}
+
+	for _, parameter := range operation.Parameters {
+		if parameter.Parameter.Name == "filter" && (parameter.Parameter.Style == nil || *parameter.Parameter.Style != "deepObject") {
+			t.Errorf("expected the filter parameter to use the deepObject style, got %+v", parameter.Parameter)
+		}
+	}
+
+	sort, ok := spec.Components.Schemas["SortOfTicket"]["items"].(map[string]any)
+	if !ok || len(sort["enum"].([]any)) != 6 {
+		t.Errorf("expected the sortable fields as enum, got %v", spec.Components.Schemas["SortOfTicket"])
+	}
+}
+
+type OwnedTicketQuery struct {
+	gocart.Query[Ticket]
+	gocart.OffsetParams
+	Owner string `path:"owner"`
+}
+
+func TestQueryWithParams(t *testing.T) {
+	var params OwnedTicketQuery
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/{owner}/tickets", gocart.O(gocart.Json[[]Ticket](), func(request *gocart.Request[OwnedTicketQuery], _ gocart.HeaderWriter) (*[]Ticket, error) {
+		params = *request.Body()
+		return &[]Ticket{}, nil
+	}))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bob/tickets?limit=5&offset=2&sort=id", nil))
+
+	if params.Limit != 5 || params.Offset != 2 || params.Owner != "bob" || len(params.Sort) != 1 {
+		t.Errorf("unexpected params %+v", params)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	router := gotrac.Default()
+	router.Options("/ping", pong)
+
+	gocart.Get(router, "/items/{id}", gocart.Json[Item](), func(request *gocart.Request[ItemPath], _ gocart.HeaderWriter) (*Item, error) {
+		return &Item{Name: "item"}, nil
+	}).WithName("getItem").WithCartInfo(func(info *gocart.CartInformation) {
+		info.WithSummary("Get an item")
+	}).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithTags("items")
+	})
+
+	gocart.Post(router, "/items", gocart.Json[Item](), gocart.Json[Item](), func(request *gocart.Request[Item], _ gocart.HeaderWriter) (*Item, error) {
+		return request.Body(), nil
+	})
+
+	gocart.Head(router, "/items", nil, func(_ *gocart.Request[struct{}], writer gocart.HeaderWriter) (*struct{}, error) {
+		writer.Header().Set("X-Total-Count", "1")
+		return nil, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"posted"}`)))
+	if recorder.Body.String() != `{"name":"posted"}` {
+		t.Errorf("unexpected body %q", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/items", nil))
+	if recorder.Header().Get("X-Total-Count") != "1" {
+		t.Errorf("expected the HEAD cart to answer, got %v", recorder.Header())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, "/ping", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the options handler to answer, got %d", recorder.Code)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation := operationOf(spec, http.MethodGet, "/items/{id}")
+	if operation.Summary == nil || *operation.Summary != "Get an item" || *operation.ID != "getItem" || operation.Tags[1] != "items" {
+		t.Errorf("unexpected operation %+v", operation)
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/gotrac"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type Item struct {
+	Name string `json:"name"`
+}
+
+type itemRepository struct {
+	items map[int]Item
+	next  int
+}
+
+func (repo *itemRepository) List(_ context.Context, params gocart.ListParams) ([]Item, int, error) {
+	var items []Item
+	for _, id := range slices.Sorted(maps.Keys(repo.items)) {
+		items = append(items, repo.items[id])
+	}
+
+	total := len(items)
+	items = items[min(params.Offset, total):min(params.Offset+params.Limit, total)]
+	return items, total, nil
+}
+
+func (repo *itemRepository) Get(_ context.Context, id int) (*Item, error) {
+	item, ok := repo.items[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
+func (repo *itemRepository) Create(_ context.Context, item *Item) (int, error) {
+	repo.next++
+	repo.items[repo.next] = *item
+	return repo.next, nil
+}
+
+func (repo *itemRepository) Update(_ context.Context, id int, item *Item) error {
+	if _, ok := repo.items[id]; !ok {
+		return gocart.ErrNotFound
+	}
+
+	repo.items[id] = *item
+	return nil
+}
+
+func (repo *itemRepository) Delete(_ context.Context, id int) error {
+	if _, ok := repo.items[id]; !ok {
+		return gocart.ErrNotFound
+	}
+
+	delete(repo.items, id)
+	return nil
+}
+
+func TestResource(t *testing.T) {
+	router := gotrac.Default()
+	gocart.NewResource[Item, int]("Items", &itemRepository{items: map[int]Item{}}).
+		Disable(gocart.OpUpdate).
+		Mount(router, "/items")
+
+	serve := func(method string, target string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/items", `{"name":"first"}`)
+	if recorder.Code != http.StatusCreated || recorder.Header().Get("Location") != "/items/1" {
+		t.Errorf("expected 201 with location /items/1, got %d with %q", recorder.Code, recorder.Header().Get("Location"))
+	}
+
+	if body := recorder.Body.String(); body != `{"name":"first"}` {
+		t.Errorf("expected the created item, got %q", body)
+	}
+
+	serve(http.MethodPost, "/items", `{"name":"second"}`)
+
+	recorder = serve(http.MethodGet, "/items?limit=1&offset=1", "")
+	if body := recorder.Body.String(); body != `{"items":[{"name":"second"}],"total":2,"prev":"0"}` || recorder.Header().Get("Link") == "" {
+		t.Errorf("unexpected list %q (link %q)", body, recorder.Header().Get("Link"))
+	}
+
+	if recorder = serve(http.MethodGet, "/items/1", ""); recorder.Body.String() != `{"name":"first"}` {
+		t.Errorf("unexpected item %q", recorder.Body.String())
+	}
+
+	if recorder = serve(http.MethodDelete, "/items/1", ""); recorder.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", recorder.Code)
+	}
+
+	if recorder = serve(http.MethodGet, "/items/1", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", recorder.Code)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if recorder = serve(method, "/items/abc", ""); recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s of an invalid id, got %d", method, recorder.Code)
+		}
+	}
+
+	if recorder = serve(http.MethodPut, "/items/2", `{"name":"updated"}`); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for the disabled update, got %d", recorder.Code)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := operationOf(spec, http.MethodPost, "/items/").Responses.MapOfResponseOrReferenceValues["201"]; !ok {
+		t.Error("expected the create operation to document 201")
+	}
+
+	if operation := operationOf(spec, http.MethodGet, "/items/{id}"); len(operation.Parameters) != 1 || operation.Parameters[0].Parameter.Schema["type"] != "integer" {
+		t.Errorf("expected the id to be documented as an integer, got %+v", operation.Parameters[0].Parameter.Schema)
+	}
+}
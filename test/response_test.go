@@ -0,0 +1,147 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type Job struct {
+	ID       string `json:"id"`
+	Location string `header:"Location" json:"-"`
+}
+
+func TestResponses(t *testing.T) {
+	ok := gocart.Status(http.StatusOK, gocart.Json[Item]())
+	accepted := gocart.Status(http.StatusAccepted, gocart.Json[Job]()).WithDescription("The item is being created")
+	undeclared := gocart.Status[Item](http.StatusTeapot, nil)
+
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/items", gocart.IO(gocart.Json[Item](), gocart.Responses(ok, accepted), func(request *gocart.Request[Item], _ gocart.HeaderWriter) (*gocart.Result, error) {
+		switch request.Body().Name {
+		case "async":
+			return accepted.Of(&Job{ID: "42", Location: "/jobs/42"}), nil
+		case "undeclared":
+			return undeclared.Of(request.Body()), nil
+		default:
+			return ok.Of(request.Body()), nil
+		}
+	}))
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve(`{"name":"sync"}`); recorder.Code != http.StatusOK || recorder.Body.String() != `{"name":"sync"}` {
+		t.Errorf("unexpected response %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	recorder := serve(`{"name":"async"}`)
+	if recorder.Code != http.StatusAccepted || recorder.Body.String() != `{"id":"42"}` || recorder.Header().Get("Location") != "/jobs/42" {
+		t.Errorf("unexpected response %d %q (location %q)", recorder.Code, recorder.Body.String(), recorder.Header().Get("Location"))
+	}
+
+	if recorder := serve(`{"name":"undeclared"}`); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for an undeclared response, got %d", recorder.Code)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses := operationOf(spec, http.MethodPost, "/items").Responses.MapOfResponseOrReferenceValues
+	if _, ok := responses["200"]; !ok {
+		t.Errorf("expected a 200 response, got %v", responses)
+	}
+
+	if response, ok := responses["202"]; !ok || response.Response.Description != "The item is being created" || response.Response.Headers["Location"].Header == nil {
+		t.Errorf("expected a 202 response with a Location header, got %+v", response.Response)
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	created := gocart.Created(gocart.Json[Item]())
+	see := gocart.Redirect(http.StatusSeeOther)
+
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/items", gocart.IO(gocart.Json[Item](), gocart.Responses(created, see), func(request *gocart.Request[Item], _ gocart.HeaderWriter) (*gocart.Result, error) {
+		if request.Body().Name == "existing" {
+			return see.At("/items/1", nil), nil
+		}
+
+		return created.At("/items/2", request.Body()), nil
+	}))
+
+	router.Method(http.MethodGet, "/legacy", gocart.O(gocart.Json[Item](), func(_ *gocart.Request[struct{}], writer gocart.HeaderWriter) (*Item, error) {
+		writer.Header().Set("Location", "/items")
+		writer.WriteHeader(http.StatusFound)
+		return &Item{Name: "ignored"}, nil
+	}))
+
+	serve := func(method string, target string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/items", `{"name":"new"}`)
+	if recorder.Code != http.StatusCreated || recorder.Header().Get("Location") != "/items/2" || recorder.Body.String() != `{"name":"new"}` {
+		t.Errorf("unexpected response %d %q (location %q)", recorder.Code, recorder.Body.String(), recorder.Header().Get("Location"))
+	}
+
+	recorder = serve(http.MethodPost, "/items", `{"name":"existing"}`)
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/items/1" || recorder.Body.Len() != 0 {
+		t.Errorf("unexpected redirect %d %q (location %q)", recorder.Code, recorder.Body.String(), recorder.Header().Get("Location"))
+	}
+
+	if recorder = serve(http.MethodGet, "/legacy", ""); recorder.Code != http.StatusFound || recorder.Body.Len() != 0 {
+		t.Errorf("expected a redirect without body, got %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses := operationOf(spec, http.MethodPost, "/items").Responses.MapOfResponseOrReferenceValues
+	for _, status := range []string{"201", "303"} {
+		if response, ok := responses[status]; !ok || response.Response.Headers["Location"].Header == nil {
+			t.Errorf("expected a %s response with a Location header, got %+v", status, response.Response)
+		}
+	}
+}
+
+func TestHeaderFields(t *testing.T) {
+	router := gotrac.Default()
+	gocart.Get(router, "/jobs/{id}", gocart.Json[Job](), func(request *gocart.Request[struct{}], _ gocart.HeaderWriter) (*Job, error) {
+		id := request.PathValue("id")
+		if id == "draft" {
+			return &Job{ID: id}, nil
+		}
+
+		return &Job{ID: id, Location: "/jobs/" + id}, nil
+	})
+
+	serve := func(id string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs/"+id, nil))
+		return recorder
+	}
+
+	// header fields are encoded next to the body
+	if recorder := serve("42"); recorder.Body.String() != `{"id":"42"}` || recorder.Header().Get("Location") != "/jobs/42" {
+		t.Errorf("unexpected response %q (location %q)", recorder.Body.String(), recorder.Header().Get("Location"))
+	}
+
+	// zero header fields are left out
+	if recorder := serve("draft"); len(recorder.Header().Values("Location")) != 0 {
+		t.Errorf("expected no location, got %q", recorder.Header().Values("Location"))
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"testing"
+)
+
+func TestRouteInfoBinding(t *testing.T) {
+	newCart := func() gocart.Cart {
+		return gocart.O(gocart.Json[Item](), func(*gocart.Request[struct{}], gocart.HeaderWriter) (*Item, error) {
+			return &Item{}, nil
+		})
+	}
+
+	router := gotrac.NewRouter()
+
+	// cart information edited after the registration
+	after := newCart()
+	route := router.Method(http.MethodGet, "/after", after).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithDescription("from the route")
+	})
+	after.WithInfo(func(info *gocart.CartInformation) {
+		info.WithSummary("from the cart").WithDescription("overridden by the route")
+	})
+
+	if info := route.Info(); info.Summary != "from the cart" || info.Description != "from the route" {
+		t.Errorf("unexpected information %+v", info)
+	}
+
+	// cart information edited before the registration
+	before := newCart().WithInfo(func(info *gocart.CartInformation) {
+		info.WithSummary("from the cart").WithHidden(true)
+	})
+	route = router.Method(http.MethodGet, "/before", before).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithHidden(false)
+	})
+
+	if info := route.Info(); info.Summary != "from the cart" || info.Hidden || info.Output == nil {
+		t.Errorf("unexpected information %+v", info)
+	}
+
+	if !before.Info().Hidden {
+		t.Error("the route must not modify the information of the cart")
+	}
+
+	// the route overrides what it sets, even if the cart had the same value at that time
+	same := newCart().WithInfo(func(info *gocart.CartInformation) {
+		info.WithSummary("X")
+	})
+	route = router.Method(http.MethodGet, "/same", same).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithSummary("X")
+	})
+	same.WithInfo(func(info *gocart.CartInformation) {
+		info.WithSummary("Y")
+	})
+
+	if info := route.Info(); info.Summary != "X" {
+		t.Errorf("expected the summary of the route, got %q", info.Summary)
+	}
+}
+
+func TestRouteInfoEditedOnce(t *testing.T) {
+	cart := gocart.O(gocart.Json[Item](), func(*gocart.Request[struct{}], gocart.HeaderWriter) (*Item, error) {
+		return &Item{}, nil
+	})
+
+	calls := 0
+	route := gotrac.NewRouter().Method(http.MethodGet, "/", cart).WithInfo(func(info *goflag.EndpointInformation) {
+		calls++
+		info.Output.HttpType = append(info.Output.HttpType, "application/xml")
+	})
+
+	for range 3 {
+		if info := route.Info(); len(info.Output.HttpType) != 2 {
+			t.Errorf("unexpected output %+v", info.Output)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the edit to be applied once, got %d", calls)
+	}
+
+	if len(cart.Info().Output.HttpType) != 1 {
+		t.Errorf("the route must not share the output of the cart, got %+v", cart.Info().Output)
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gocrew"
+	"github.com/benni-tec/gocart/goflag"
+	"github.com/benni-tec/gocart/gotrac"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type FilePath struct {
+	Path string `path:"path"`
+}
+
+func TestServeMux(t *testing.T) {
+	var matched string
+	router := gotrac.NewServeMux()
+	router.Use(middleware.RequestID, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := gotrac.CurrentRoute(r.Context()); route != nil {
+				matched = route.Method + " " + route.Pattern
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	router.Get("/ping", pong)
+	router.Route("/items", func(r gotrac.Router) {
+		r.Get("/", pong)
+		r.Handle("DELETE /{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.PathValue("id")))
+		})).WithInfo(func(info *goflag.EndpointInformation) {
+			info.WithInput(gotrac.None[ItemPath]())
+		})
+	})
+
+	legacy := gotrac.NewRouter()
+	legacy.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("legacy"))
+	}).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithInput(gotrac.None[ItemPath]())
+	})
+	router.Mount("/legacy", legacy)
+
+	router.Get("/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.PathValue("path")))
+	}).WithInfo(func(info *goflag.EndpointInformation) {
+		info.WithInput(gotrac.None[FilePath]())
+	})
+	router.Get("/docs/{$}", pong)
+
+	gocart.Get(router, "/carts", gocart.Json[Item](), func(*gocart.Request[struct{}], gocart.HeaderWriter) (*Item, error) {
+		return &Item{Name: "cart"}, nil
+	})
+
+	serve := func(method string, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	if recorder := serve(http.MethodDelete, "/items/42"); recorder.Body.String() != "42" || matched != "DELETE /items/{id}" {
+		t.Errorf("unexpected response %q for %q", recorder.Body.String(), matched)
+	}
+
+	if recorder := serve(http.MethodGet, "/items"); recorder.Code != http.StatusOK || matched != "GET /items/" {
+		t.Errorf("unexpected response %d for %q", recorder.Code, matched)
+	}
+
+	if recorder := serve(http.MethodGet, "/files/a/b.txt"); recorder.Body.String() != "a/b.txt" {
+		t.Errorf("unexpected response %q", recorder.Body.String())
+	}
+
+	if recorder := serve(http.MethodGet, "/legacy/7"); recorder.Body.String() != "legacy" || matched != "GET /legacy/{id}" {
+		t.Errorf("unexpected response %q for %q", recorder.Body.String(), matched)
+	}
+
+	if recorder := serve(http.MethodHead, "/carts"); recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Errorf("unexpected HEAD response %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	if recorder := serve(http.MethodPost, "/items/42"); recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Get("Allow") != "DELETE, OPTIONS" {
+		t.Errorf("unexpected 405 response %d %q", recorder.Code, recorder.Header().Get("Allow"))
+	}
+
+	if recorder := serve(http.MethodGet, "/unknown"); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", recorder.Code)
+	}
+
+	spec, err := gocrew.OpenApi31("Test Documentation", nil).Generate(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if operationOf(spec, http.MethodDelete, "/items/{id}") == nil || operationOf(spec, http.MethodGet, "/legacy/{id}") == nil || operationOf(spec, http.MethodGet, "/carts") == nil ||
+		operationOf(spec, http.MethodGet, "/files/{path}") == nil || operationOf(spec, http.MethodGet, "/docs/") == nil {
+		t.Errorf("unexpected paths %v", spec.Paths.MapOfPathItemValues)
+	}
+}
+
+func TestServeMuxConflict(t *testing.T) {
+	expectPanic := func(name string, fn func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected %s to panic", name)
+			}
+		}()
+
+		fn()
+	}
+
+	router := gotrac.NewServeMux()
+	router.Get("/ok", pong)
+	router.Get("/a/{x}", pong)
+	expectPanic("a conflicting pattern", func() { router.Get("/a/{y}", pong) })
+
+	sub := gotrac.NewServeMux()
+	sub.Get("/{id}", pong)
+	router.Mount("/b", sub)
+	expectPanic("a conflicting pattern of a mounted router", func() { sub.Get("/{name}", pong) })
+	expectPanic("a conflicting mount", func() {
+		other := gotrac.NewServeMux()
+		other.Get("/", pong)
+		router.Mount("/a/{x}", other)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the other routes to be served, got %d", recorder.Code)
+	}
+
+	// routes registered after the first request are served as well
+	router.Get("/late", pong)
+	sub.Get("/{id}/late", pong)
+	for _, target := range []string{"/late", "/b/1/late"} {
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("expected %s to be served, got %d", target, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func pingService(_ context.Context, request PingRequest) (PongResponse, error) {
+	return PongResponse{Pong: request.N%2 == 0}, nil
+}
+
+func TestService(t *testing.T) {
+	var received PingRequest
+
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/ping", gocart.Service(gocart.Json[PingRequest](), gocart.Json[PongResponse](), pingService))
+	router.Method(http.MethodPost, "/consume", gocart.Consumer(gocart.Json[PingRequest](), func(ctx context.Context, request PingRequest) error {
+		if ctx == nil {
+			return errors.New("missing context")
+		}
+
+		received = request
+		return nil
+	}))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/ping", strings.NewReader(`{"n":4}`)))
+
+	if body := recorder.Body.String(); body != `{"pong":true}` {
+		t.Errorf("expected %q, got %q", `{"pong":true}`, body)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/consume", strings.NewReader(`{"n":7}`)))
+
+	if recorder.Code != http.StatusNoContent || received.N != 7 {
+		t.Errorf("expected 204 and n=7, got %d and n=%d", recorder.Code, received.N)
+	}
+}
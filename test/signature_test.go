@@ -0,0 +1,64 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	secret := []byte("secret")
+
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/webhook", gocart.I(gocart.Json[PingRequest](), func(request *gocart.Request[PingRequest], writer gocart.HeaderWriter) (*any, error) {
+		writer.Header().Set("X-Raw", string(request.Raw()))
+		return nil, nil
+	}).WithVerifier(gocart.Signature(gocart.SlackSignature, gocart.Secrets(secret), 5*time.Minute)))
+
+	sign := func(timestamp time.Time, body string) *http.Request {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte("v0:" + ts + ":" + body))
+
+		request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		request.Header.Set("X-Slack-Request-Timestamp", ts)
+		request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		return request
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, sign(time.Now(), `{"n":1}`))
+
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, recorder.Code, recorder.Body.String())
+	}
+
+	if raw := recorder.Header().Get("X-Raw"); raw != `{"n":1}` {
+		t.Errorf("expected raw body %q, got %q", `{"n":1}`, raw)
+	}
+
+	tampered := sign(time.Now(), `{"n":1}`)
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"n":2}`)).Body
+
+	for name, request := range map[string]*http.Request{
+		"replayed": sign(time.Now().Add(-time.Hour), `{"n":1}`),
+		"tampered": tampered,
+		"unsigned": httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"n":1}`)),
+	} {
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusUnauthorized, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplate(t *testing.T) {
+	templates := fstest.MapFS{
+		"layout.html": {Data: []byte(`{{define "layout"}}<html>{{template "content" .}}</html>{{end}}`)},
+		"pong.html":   {Data: []byte(`{{define "content"}}pong={{.Pong}}{{end}}`)},
+	}
+
+	html, err := gocart.Template[PongResponse](templates, "layout", "layout.html", "pong.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/ping", gocart.O(gocart.Negotiate(html, gocart.Json[PongResponse]()), func(_ *gocart.Request[any], _ gocart.HeaderWriter) (*PongResponse, error) {
+		return &PongResponse{Pong: true}, nil
+	}))
+
+	for accept, expected := range map[string]string{
+		"text/html,application/xhtml+xml;q=0.9": "<html>pong=true</html>",
+		"application/json":                      `{"pong":true}`,
+		"":                                      "<html>pong=true</html>",
+		"image/png":                             "<html>pong=true</html>",
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		request.Header.Set("Accept", accept)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if body := strings.TrimSpace(recorder.Body.String()); body != expected {
+			t.Errorf("Accept %q: expected %q, got %q", accept, expected, body)
+		}
+
+		if vary := recorder.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", accept, vary)
+		}
+	}
+
+	_, err = gocart.Template[PongResponse](templates, "layout", "missing.html")
+	if err == nil {
+		t.Error("expected an error for a missing template")
+	}
+}
+
+func TestMarshalSerializers(t *testing.T) {
+	item := &Item{Name: "cart"}
+	for serializer, expected := range map[gocart.Serializer[Item]]string{
+		gocart.Json[Item](): `{"name":"cart"}`,
+		gocart.Yaml[Item](): "name: cart",
+		gocart.Xml[Item]():  "<Item><Name>cart</Name></Item>",
+	} {
+		data, err := serializer.Serialize(item, http.Header{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body := strings.TrimSpace(string(data)); body != expected {
+			t.Errorf("%v: expected %q, got %q", serializer.Type().HttpType, expected, body)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	router := gotrac.Default()
+	router.Method(http.MethodGet, "/yaml", gocart.O(gocart.Yaml[Item](), func(*gocart.Request[struct{}], gocart.HeaderWriter) (*Item, error) {
+		return &Item{Name: "cart"}, nil
+	}))
+
+	for accept, expected := range map[string]string{"": "application/x-yaml", "text/yaml": "text/yaml"} {
+		request := httptest.NewRequest(http.MethodGet, "/yaml", nil)
+		request.Header.Set("Accept", accept)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if values := recorder.Header().Values("Content-Type"); len(values) != 1 || values[0] != expected {
+			t.Errorf("expected the single Content-Type %s for %q, got %v", expected, accept, values)
+		}
+	}
+}
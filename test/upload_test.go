@@ -0,0 +1,85 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/benni-tec/gocart/gocart"
+	"github.com/benni-tec/gocart/gotrac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUpload(t *testing.T) {
+	router := gotrac.Default()
+	router.Method(http.MethodPost, "/spool", gocart.I(gocart.Spool(16, ""), func(request *gocart.Request[gocart.SpooledUpload], writer gocart.HeaderWriter) (*any, error) {
+		upload := request.Body()
+		data, err := io.ReadAll(upload)
+		if err != nil {
+			return nil, err
+		}
+
+		writer.Header().Set("X-Checksum", hex.EncodeToString(upload.Checksum))
+		writer.Header().Set("X-Length", strconv.FormatInt(upload.Length, 10))
+		writer.Header().Set("X-Body", string(data))
+		return nil, nil
+	}))
+	router.Method(http.MethodPost, "/stream", gocart.I(gocart.Stream(16), func(request *gocart.Request[gocart.Upload], _ gocart.HeaderWriter) (*any, error) {
+		_, err := io.Copy(io.Discard, request.Body())
+		return nil, err
+	}))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/spool", strings.NewReader("hello world")))
+
+	checksum := sha256.Sum256([]byte("hello world"))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+
+	if got := recorder.Header().Get("X-Checksum"); got != hex.EncodeToString(checksum[:]) {
+		t.Errorf("unexpected checksum %q", got)
+	}
+
+	if got := recorder.Header().Get("X-Length"); got != "11" {
+		t.Errorf("expected length 11, got %q", got)
+	}
+
+	if got := recorder.Header().Get("X-Body"); got != "hello world" {
+		t.Errorf("expected body %q, got %q", "hello world", got)
+	}
+
+	// declared too large
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/spool", strings.NewReader(strings.Repeat("x", 32))))
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+
+	// unknown length, but too large
+	request := httptest.NewRequest(http.MethodPost, "/stream", io.MultiReader(strings.NewReader(strings.Repeat("x", 32))))
+	request.ContentLength = -1
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+
+	// declared longer than the body
+	request = httptest.NewRequest(http.MethodPost, "/stream", strings.NewReader("short"))
+	request.ContentLength = 8
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	if data, err := gocart.Stream(0).Serialize(nil, http.Header{}); data != nil || err != nil {
+		t.Errorf("expected an empty body, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"github.com/benni-tec/gocart/gotrac"
+	"net/http"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	router := gotrac.NewRouter()
+	router.Get("/ping", pong)
+	router.Get("/ping", pong)
+	router.Get("/users/{id}", pong)
+	router.Get("/users/me", pong)
+	router.Delete("/users/{userID}", pong)
+	router.Get("/items/{id:[0-9]+}", pong)
+	router.Get("/items/latest", pong)
+	router.Route("/api", func(r gotrac.Router) {
+		r.Get("/status", pong)
+	})
+	router.Get("/api/version", pong)
+
+	diagnostics := gotrac.Validate(router)
+	if diagnostics.Err() == nil {
+		t.Fatal("expected the routes to conflict")
+	}
+
+	expect := func(kind gotrac.DiagnosticKind, method string, pattern string, other string) {
+		for _, diagnostic := range diagnostics.Of(kind) {
+			if diagnostic.Method == method && diagnostic.Pattern == pattern && diagnostic.Other == other {
+				return
+			}
+		}
+
+		t.Errorf("expected %s diagnostic for %s %s and %s, got %v", kind, method, pattern, other, diagnostics)
+	}
+
+	expect(gotrac.DuplicateRoute, http.MethodGet, "/ping", "/ping")
+	expect(gotrac.ShadowedRoute, http.MethodGet, "/users/{id}", "/users/me")
+	expect(gotrac.ParamMismatch, http.MethodDelete, "/users/{userID}", "/users/{id}")
+	expect(gotrac.MountConflict, http.MethodGet, "/api/version", "/api/*")
+
+	// the regular expression does not match latest
+	if len(diagnostics) != 4 {
+		t.Errorf("expected 4 diagnostics, got %v", diagnostics)
+	}
+
+	if errs := diagnostics.Errors(); len(errs) != 3 || len(errs.Of(gotrac.ShadowedRoute)) != 0 {
+		t.Errorf("expected only the shadowed route to be a warning, got %v", errs)
+	}
+
+	// the layout from the README is no error
+	users := gotrac.NewRouter()
+	users.Get("/users/{id}", pong)
+	users.Get("/users/me", pong)
+	if diagnostics := gotrac.Validate(users); len(diagnostics) != 1 || diagnostics.Err() != nil {
+		t.Errorf("expected a single warning, got %v", diagnostics)
+	}
+
+	mux := gotrac.NewServeMux()
+	mux.Handle("GET /files/{path...}", http.HandlerFunc(pong))
+	mux.Get("/files/readme", pong)
+	if shadowed := gotrac.Validate(mux).Of(gotrac.ShadowedRoute); len(shadowed) != 1 || shadowed[0].Pattern != "/files/{path...}" {
+		t.Errorf("unexpected diagnostics %v", shadowed)
+	}
+
+	// chi prefers /a/c/{y}, the http.ServeMux treats them as conflicting
+	inner := gotrac.NewServeMux()
+	inner.Get("/c/{y}", pong)
+	outer := gotrac.NewServeMux()
+	outer.Get("/a/{x}/b", pong)
+	outer.Mount("/a", gotrac.WithName("inner", inner))
+	if conflicts := gotrac.Validate(outer).Of(gotrac.ConflictingRoute); len(conflicts) != 1 || conflicts[0].Severity != gotrac.Error {
+		t.Errorf("expected a conflict, got %v", gotrac.Validate(outer))
+	}
+
+	chiRouter := gotrac.NewRouter()
+	chiRouter.Get("/a/{x}/b", pong)
+	chiRouter.Get("/a/c/{y}", pong)
+	if diagnostics := gotrac.Validate(chiRouter); len(diagnostics) != 1 || diagnostics[0].Kind != gotrac.ShadowedRoute {
+		t.Errorf("expected a shadowed route, got %v", diagnostics)
+	}
+}